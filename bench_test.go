@@ -0,0 +1,65 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func benchmarkReaders(b *testing.B, f func(r io.Reader) error) {
+	data := testVorbis([]string{"LOOPSTART=1000", "LOOPLENGTH=5000"}, 2000)
+	for _, seekable := range []bool{true, false} {
+		name := "Seekable"
+		if !seekable {
+			name = "NonSeekable"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				var r io.Reader = bytes.NewReader(data)
+				if !seekable {
+					r = struct{ io.Reader }{r}
+				}
+				if err := f(r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	benchmarkReaders(b, func(r io.Reader) error {
+		_, _, err := Read(r)
+		return err
+	})
+}
+
+func BenchmarkReadInfo(b *testing.B) {
+	benchmarkReaders(b, func(r io.Reader) error {
+		_, err := ReadInfo(r)
+		return err
+	})
+}
+
+func BenchmarkReadAll(b *testing.B) {
+	benchmarkReaders(b, func(r io.Reader) error {
+		_, err := ReadAll(r)
+		return err
+	})
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"fmt"
+	"io"
+)
+
+// Result is the information of a Vorbis, Opus or FLAC logical stream in a chained or multiplexed Ogg
+// stream.
+type Result struct {
+	// Chain is the index of the chain the logical stream belongs to.
+	// A chain is a group of logical streams that starts with beginning-of-stream pages. Concatenated Ogg
+	// files are chained.
+	Chain int
+
+	// Serial is the serial number of the logical stream.
+	Serial uint32
+
+	Info
+}
+
+type chainedStream struct {
+	codec   codec
+	ident   []byte
+	comment []byte
+
+	granule int64
+
+	// size is the total size of the pages of the logical stream in bytes.
+	size int64
+}
+
+// ReadAll reads the given src as an Ogg stream and returns the Results of all the Vorbis, Opus and FLAC
+// logical streams, in the order of the chains and the beginning-of-stream pages.
+//
+// Unlike Read, ReadAll reads the whole stream.
+func ReadAll(src io.Reader) ([]Result, error) {
+	return NewDecoder(src, nil).ReadAll()
+}
+
+func readAll(pr *pageReader, tags *tagMatcher) ([]Result, error) {
+	results, _, err := readChains(pr, tags)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// readChains reads the whole of pr and returns the Results and the header packets of all the Vorbis, Opus
+// and FLAC logical streams.
+func readChains(pr *pageReader, tags *tagMatcher) ([]Result, []*chainedStream, error) {
+	var (
+		results []Result
+		streams []*chainedStream
+
+		// serials maps the serial numbers of the logical streams to the indices of results.
+		// The other logical streams are mapped to -1.
+		serials map[uint32]int
+
+		// active is the number of the logical streams in the current chain that have not ended yet.
+		active int
+
+		chain = -1
+	)
+
+	r := newPacketReader(pr)
+	for {
+		p, packets, err := r.next(func(p *page) bool {
+			if p.headerType&headerTypeBOS != 0 {
+				return false
+			}
+			// Only the pages for the comment headers are needed.
+			idx, ok := serials[p.serial]
+			return ok && (idx == -1 || streams[idx].comment != nil)
+		})
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if p.headerType&headerTypeBOS != 0 {
+			// A beginning-of-stream page after all the logical streams end starts a new chain.
+			if active == 0 {
+				chain++
+				serials = map[uint32]int{}
+			}
+			if _, ok := serials[p.serial]; ok {
+				return nil, nil, fmt.Errorf("%w: duplicated serial number: %d", ErrCorruptPage, p.serial)
+			}
+			active++
+			serials[p.serial] = -1
+			if c := detectCodec(p.body); c != 0 {
+				serials[p.serial] = len(results)
+				results = append(results, Result{
+					Chain:  chain,
+					Serial: p.serial,
+				})
+				streams = append(streams, &chainedStream{
+					codec:   c,
+					ident:   p.body,
+					granule: -1,
+					size:    p.size(),
+				})
+			}
+			continue
+		}
+
+		idx, ok := serials[p.serial]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: unknown serial number: %d", ErrCorruptPage, p.serial)
+		}
+		if p.headerType&headerTypeEOS != 0 {
+			active--
+		}
+		if idx == -1 {
+			continue
+		}
+
+		s := streams[idx]
+		s.size += p.size()
+		if p.granule != -1 {
+			s.granule = p.granule
+		}
+		if s.comment != nil {
+			continue
+		}
+
+		if len(packets) == 0 {
+			if err := pr.checkCommentSize(r.partialSize(p.serial)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if err := pr.checkCommentSize(len(packets[0])); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := commentData(s.codec, packets[0]); !ok {
+			return nil, nil, ErrNoCommentHeader
+		}
+		s.comment = packets[0]
+	}
+
+	if pr.pages == 0 {
+		return nil, nil, ErrNotOgg
+	}
+	if len(results) == 0 {
+		return nil, nil, ErrNoVorbisStream
+	}
+	for i, s := range streams {
+		if s.comment == nil {
+			return nil, nil, ErrNoCommentHeader
+		}
+		info, err := newInfo(s.codec, s.ident, s.comment, s.granule, tags)
+		if err != nil {
+			return nil, nil, err
+		}
+		results[i].Info = info
+	}
+	return results, streams, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadAll(t *testing.T) {
+	// A multiplexed chain followed by an Opus chain and a Vorbis chain without loop tags.
+	var data []byte
+	data = append(data, testMultiplexed()...)
+	data = append(data, testOpus([]string{"LOOPSTART=3", "LOOPLENGTH=30"}, 2)...)
+	data = append(data, testVorbis(nil, 1)...)
+
+	rs, err := ReadAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		chain      int
+		serial     uint32
+		start      int64
+		length     int64
+		total      int64
+		sampleRate int
+	}
+	var got []result
+	for _, r := range rs {
+		got = append(got, result{
+			chain:      r.Chain,
+			serial:     r.Serial,
+			start:      r.LoopStart,
+			length:     r.LoopLength,
+			total:      r.TotalSamples,
+			sampleRate: r.SampleRate,
+		})
+	}
+	want := []result{
+		{chain: 0, serial: 1, start: 1, length: 10, total: 1000, sampleRate: 44100},
+		{chain: 0, serial: 2, start: 2, length: 20, total: 2000, sampleRate: 44100},
+		{chain: 1, serial: 5678, start: 3, length: 30, total: 2 * 960, sampleRate: 48000},
+		{chain: 2, serial: 1234, start: 0, length: 0, total: 1000, sampleRate: 44100},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("result %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if _, err := ReadAll(bytes.NewReader([]byte("junk"))); err != ErrNotOgg {
+		t.Errorf("got %v, want %v", err, ErrNotOgg)
+	}
+}
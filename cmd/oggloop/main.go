@@ -0,0 +1,222 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// oggloop is a command to show and edit LOOPSTART and LOOPLENGTH meta data of Ogg files.
+//
+// Usage:
+//
+//	oggloop show [--json] file.ogg...
+//	oggloop set --start N --length M file.ogg...
+//	oggloop strip file.ogg...
+//	oggloop repair-crc file.ogg...
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/oggloop"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  oggloop show [--json] file.ogg...
+  oggloop set --start N --length M file.ogg...
+  oggloop strip file.ogg...
+  oggloop repair-crc file.ogg...`)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "show":
+		err = show(args)
+	case "set":
+		err = set(args)
+	case "strip":
+		err = strip(args)
+	case "repair-crc":
+		err = repairCRC(args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type showResult struct {
+	File         string `json:"file"`
+	SampleRate   int    `json:"sampleRate"`
+	Channels     int    `json:"channels"`
+	TotalSamples int64  `json:"totalSamples"`
+	LoopStart    int64  `json:"loopStart"`
+	LoopLength   int64  `json:"loopLength"`
+
+	// HasLoopStart and HasLoopLength tell whether the tags exist, as the values are 0 when they do not.
+	HasLoopStart  bool `json:"hasLoopStart"`
+	HasLoopLength bool `json:"hasLoopLength"`
+}
+
+func show(args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "output in JSON")
+	_ = fs.Parse(args)
+
+	var results []showResult
+	for _, name := range fs.Args() {
+		info, err := readInfo(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		results = append(results, showResult{
+			File:          name,
+			SampleRate:    info.SampleRate,
+			Channels:      info.Channels,
+			TotalSamples:  info.TotalSamples,
+			LoopStart:     info.LoopStart,
+			LoopLength:    info.LoopLength,
+			HasLoopStart:  info.HasLoopStart,
+			HasLoopLength: info.HasLoopLength,
+		})
+	}
+
+	if *asJSON {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		return e.Encode(results)
+	}
+	for _, r := range results {
+		if len(results) > 1 {
+			fmt.Printf("%s:\n", r.File)
+		}
+		fmt.Printf("sample rate:   %d\n", r.SampleRate)
+		fmt.Printf("channels:      %d\n", r.Channels)
+		fmt.Printf("total samples: %d\n", r.TotalSamples)
+		fmt.Printf("start:         %s\n", tagValue(r.LoopStart, r.HasLoopStart))
+		fmt.Printf("length:        %s\n", tagValue(r.LoopLength, r.HasLoopLength))
+	}
+	return nil
+}
+
+// tagValue formats the value of a loop tag, or "none" when the tag does not exist.
+func tagValue(v int64, ok bool) string {
+	if !ok {
+		return "none"
+	}
+	return fmt.Sprint(v)
+}
+
+func readInfo(name string) (oggloop.Info, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return oggloop.Info{}, err
+	}
+	defer f.Close()
+	return oggloop.ReadInfo(f)
+}
+
+func set(args []string) error {
+	fs := flag.NewFlagSet("set", flag.ExitOnError)
+	start := fs.Int64("start", -1, "LOOPSTART in samples")
+	length := fs.Int64("length", -1, "LOOPLENGTH in samples")
+	_ = fs.Parse(args)
+
+	if *start < 0 || *length < 0 {
+		return fmt.Errorf("oggloop: both --start and --length must be specified")
+	}
+	for _, name := range fs.Args() {
+		if err := rewriteFile(name, func(dst io.Writer, src io.Reader) error {
+			return oggloop.SetLoop(dst, src, *start, *length)
+		}); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func strip(args []string) error {
+	fs := flag.NewFlagSet("strip", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	for _, name := range fs.Args() {
+		if err := rewriteFile(name, oggloop.StripLoop); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// repairCRC recomputes the page checksums of files edited by other tools, e.g. a hex editor.
+func repairCRC(args []string) error {
+	fs := flag.NewFlagSet("repair-crc", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	for _, name := range fs.Args() {
+		if err := rewriteFile(name, oggloop.RepairCRC); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// rewriteFile rewrites the file name with f. The file is replaced only when f succeeds.
+func rewriteFile(name string, f func(dst io.Writer, src io.Reader) error) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := f(tmp, src); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(fi.Mode()); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := src.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
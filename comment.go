@@ -0,0 +1,150 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+var errBrokenComment = fmt.Errorf("%w: broken comment header", ErrCorruptPage)
+
+// Comments is the vendor string and the comments of a comment header.
+// https://www.xiph.org/vorbis/doc/v-comment.html
+type Comments struct {
+	// Vendor is the vendor string.
+	Vendor string
+
+	// Fields is the list of the comments in the form of KEY=value, in the order of the comment header.
+	Fields []string
+}
+
+// Get returns the values of the comments with the given key. Keys are case-insensitive.
+func (c *Comments) Get(key string) []string {
+	var values []string
+	for _, f := range c.Fields {
+		k, v, ok := strings.Cut(f, "=")
+		if ok && strings.EqualFold(k, key) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Add adds a comment with the given key and value.
+func (c *Comments) Add(key, value string) {
+	c.Fields = append(c.Fields, key+"="+value)
+}
+
+// Remove removes all the comments with the given key. Keys are case-insensitive.
+func (c *Comments) Remove(key string) {
+	fields := c.Fields[:0]
+	for _, f := range c.Fields {
+		k, _, ok := strings.Cut(f, "=")
+		if ok && strings.EqualFold(k, key) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	c.Fields = fields
+}
+
+// Set replaces all the comments with the given key with a single comment.
+func (c *Comments) Set(key, value string) {
+	c.Remove(key)
+	c.Add(key, value)
+}
+
+// validate returns an error if a field is not in the form of KEY=value, or the key has a character not
+// allowed by the specification.
+func (c *Comments) validate() error {
+	for _, f := range c.Fields {
+		k, _, ok := strings.Cut(f, "=")
+		if !ok {
+			return fmt.Errorf("oggloop: comment %q does not have '='", f)
+		}
+		for i := 0; i < len(k); i++ {
+			// A field name consists of the ASCII characters from 0x20 to 0x7d except '='.
+			if k[i] < 0x20 || 0x7d < k[i] {
+				return fmt.Errorf("oggloop: invalid field name %q", k)
+			}
+		}
+	}
+	return nil
+}
+
+// vorbisComment is the content of a comment header packet after its signature.
+type vorbisComment struct {
+	Comments
+
+	// trailer is the bytes after the comments, e.g. the framing bit.
+	trailer []byte
+}
+
+func parseComment(data []byte) (*vorbisComment, error) {
+	readString := func() (string, bool) {
+		if len(data) < 4 {
+			return "", false
+		}
+		n := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		if uint64(len(data)) < uint64(n) {
+			return "", false
+		}
+		s := string(data[:n])
+		data = data[n:]
+		return s, true
+	}
+
+	c := &vorbisComment{}
+	vendor, ok := readString()
+	if !ok {
+		return nil, errBrokenComment
+	}
+	c.Vendor = vendor
+
+	if len(data) < 4 {
+		return nil, errBrokenComment
+	}
+	n := binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	for i := uint32(0); i < n; i++ {
+		f, ok := readString()
+		if !ok {
+			return nil, errBrokenComment
+		}
+		c.Fields = append(c.Fields, f)
+	}
+	c.trailer = data
+	return c, nil
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func (c *vorbisComment) bytes() []byte {
+	var buf []byte
+	buf = appendUint32(buf, uint32(len(c.Vendor)))
+	buf = append(buf, c.Vendor...)
+	buf = appendUint32(buf, uint32(len(c.Fields)))
+	for _, f := range c.Fields {
+		buf = appendUint32(buf, uint32(len(f)))
+		buf = append(buf, f...)
+	}
+	buf = append(buf, c.trailer...)
+	return buf
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// crcTable is the table for Ogg's CRC-32: the polynomial is 0x04c11db7, and neither the input nor the
+// output is reflected.
+// https://www.xiph.org/ogg/doc/framing.html
+var crcTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = (r << 1) ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+func updateCRC(crc uint32, b []byte) uint32 {
+	for _, x := range b {
+		crc = (crc << 8) ^ crcTable[byte(crc>>24)^x]
+	}
+	return crc
+}
+
+// pageCRC returns the checksum of the given whole page, treating the checksum field as zeros.
+func pageCRC(page []byte) uint32 {
+	crc := updateCRC(0, page[:22])
+	crc = updateCRC(crc, []byte{0, 0, 0, 0})
+	return updateCRC(crc, page[26:])
+}
+
+// splitPages splits the given buf into whole pages.
+// splitPages returns an error when buf is not a sequence of well-formed pages.
+func splitPages(buf []byte) ([][]byte, error) {
+	if !bytes.HasPrefix(buf, []byte("OggS")) {
+		return nil, ErrNotOgg
+	}
+	var pages [][]byte
+	for offset := 0; offset < len(buf); {
+		p := buf[offset:]
+		if len(p) < 27 || string(p[:4]) != "OggS" {
+			return nil, fmt.Errorf("%w: broken page header at offset %d", ErrCorruptPage, offset)
+		}
+		if p[4] != 0 {
+			return nil, fmt.Errorf("%w: unsupported page version %d at offset %d", ErrCorruptPage, p[4], offset)
+		}
+		nseg := int(p[26])
+		if len(p) < 27+nseg {
+			return nil, fmt.Errorf("%w: truncated segment table at offset %d", ErrCorruptPage, offset)
+		}
+		size := 27 + nseg
+		for _, s := range p[27 : 27+nseg] {
+			size += int(s)
+		}
+		if len(p) < size {
+			return nil, fmt.Errorf("%w: truncated page at offset %d", ErrCorruptPage, offset)
+		}
+		pages = append(pages, p[:size])
+		offset += size
+	}
+	return pages, nil
+}
+
+// RepairCRC copies the Ogg stream src to dst, recomputing the checksum of every page.
+// RepairCRC is for a stream whose checksums were invalidated by editing bytes directly.
+//
+// RepairCRC verifies that src is a sequence of well-formed pages before writing anything to dst,
+// and returns an error if not.
+func RepairCRC(dst io.Writer, src io.Reader) error {
+	buf, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	pages, err := splitPages(buf)
+	if err != nil {
+		return err
+	}
+	for _, p := range pages {
+		binary.LittleEndian.PutUint32(p[22:26], pageCRC(p))
+	}
+	_, err = dst.Write(buf)
+	return err
+}
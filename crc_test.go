@@ -0,0 +1,118 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRepairCRC(t *testing.T) {
+	stream := testVorbis([]string{"LOOPSTART=10", "LOOPLENGTH=20"}, 2)
+
+	// Edit a value directly, which invalidates the checksum of the comment page.
+	edited := bytes.Replace(stream, []byte("LOOPSTART=10"), []byte("LOOPSTART=42"), 1)
+	if _, _, err := NewDecoder(bytes.NewReader(edited), &DecoderOptions{VerifyCRC: true}).Read(); !errors.Is(err, ErrCorruptPage) {
+		t.Fatalf("Read before RepairCRC: got %v, want ErrCorruptPage", err)
+	}
+	var buf bytes.Buffer
+	if err := RepairCRC(&buf, bytes.NewReader(edited)); err != nil {
+		t.Fatal(err)
+	}
+	start, length, err := NewDecoder(bytes.NewReader(buf.Bytes()), &DecoderOptions{VerifyCRC: true}).Read()
+	if err != nil {
+		t.Fatalf("Read after RepairCRC: %v", err)
+	}
+	if start != 42 || length != 20 {
+		t.Errorf("Read after RepairCRC: got (%d, %d), want (42, 20)", start, length)
+	}
+	// Only the checksum of the comment page changes.
+	if got, want := buf.Len(), len(edited); got != want {
+		t.Errorf("got %d bytes, want %d", got, want)
+	}
+	var diffs int
+	for i := range edited {
+		if buf.Bytes()[i] != edited[i] {
+			diffs++
+		}
+	}
+	if diffs == 0 || diffs > 4 {
+		t.Errorf("got %d changed bytes, want 1 to 4", diffs)
+	}
+
+	// A healthy stream is copied as it is.
+	buf.Reset()
+	if err := RepairCRC(&buf, bytes.NewReader(stream)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), stream) {
+		t.Errorf("RepairCRC changed a healthy stream")
+	}
+}
+
+func TestRepairCRCMalformed(t *testing.T) {
+	stream := testVorbis([]string{"LOOPSTART=10", "LOOPLENGTH=20"}, 2)
+	version := append([]byte{}, stream...)
+	version[4] = 1
+
+	testCases := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{
+			name: "not Ogg",
+			data: []byte("RIFF"),
+			err:  ErrNotOgg,
+		},
+		{
+			name: "empty",
+			data: nil,
+			err:  ErrNotOgg,
+		},
+		{
+			name: "truncated page",
+			data: stream[:len(stream)-1],
+			err:  ErrCorruptPage,
+		},
+		{
+			name: "truncated segment table",
+			// The last page is 128 bytes: a 27-byte header, a segment and a 100-byte body.
+			data: stream[:len(stream)-128+27],
+			err:  ErrCorruptPage,
+		},
+		{
+			name: "trailing junk",
+			data: append(append([]byte{}, stream...), "junk"...),
+			err:  ErrCorruptPage,
+		},
+		{
+			name: "unsupported version",
+			data: version,
+			err:  ErrCorruptPage,
+		},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := RepairCRC(&buf, bytes.NewReader(tc.data)); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+		}
+		// Nothing is written for a malformed stream.
+		if buf.Len() != 0 {
+			t.Errorf("%s: got %d bytes written, want 0", tc.name, buf.Len())
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"context"
+	"io"
+)
+
+// DecoderOptions represents options for a Decoder.
+//
+// The limits are useful to read untrusted input, e.g. uploaded files, with bounded memory and time.
+type DecoderOptions struct {
+	// VerifyCRC specifies whether the Decoder verifies the checksum of every page it reads.
+	// When a checksum does not match, the Decoder returns an error wrapping ErrCorruptPage.
+	//
+	// With VerifyCRC, the Decoder reads every page entirely instead of skipping or seeking.
+	//
+	// The default (zero) value is false.
+	VerifyCRC bool
+
+	// SkipCorruptPages specifies whether the Decoder ignores pages whose checksums do not match, as if the
+	// pages did not exist, instead of returning an error.
+	// SkipCorruptPages works only with VerifyCRC.
+	//
+	// The default (zero) value is false.
+	SkipCorruptPages bool
+
+	// Resync specifies whether the Decoder scans forward for the next capture pattern "OggS" when the
+	// stream has bytes that are not a page, e.g., junk prepended to the stream or a damaged page, instead
+	// of returning an error.
+	// With VerifyCRC, a page whose checksum does not match is also scanned over, as the capture pattern
+	// might appear in junk by chance.
+	//
+	// The default (zero) value is false.
+	Resync bool
+
+	// MaxPages is the maximum number of pages the Decoder reads.
+	// When the Decoder would read more pages, the Decoder returns an error wrapping ErrLimitExceeded.
+	//
+	// The default (zero) value means no limit.
+	MaxPages int
+
+	// MaxCommentSize is the maximum size of a comment header packet in bytes.
+	// When a comment header packet is larger, the Decoder returns an error wrapping ErrLimitExceeded.
+	//
+	// The default (zero) value means no limit.
+	MaxCommentSize int
+
+	// MaxBytes is the maximum number of bytes the Decoder reads or skips in the stream.
+	// When the Decoder would read more bytes, the Decoder returns an error wrapping ErrLimitExceeded.
+	//
+	// The default (zero) value means no limit.
+	MaxBytes int64
+
+	// CaseInsensitiveKeys specifies whether the Decoder matches the keys of the loop tags
+	// case-insensitively, e.g. "loopstart" as LOOPSTART. The field names of Vorbis comments are
+	// case-insensitive by the specification.
+	//
+	// The default (zero) value is false.
+	CaseInsensitiveKeys bool
+
+	// AllowSpaces specifies whether the Decoder allows spaces and tabs around "=" of the loop tags, e.g.
+	// "LOOPSTART = 44100".
+	//
+	// The default (zero) value is false.
+	AllowSpaces bool
+
+	// LoopStartKeys is the alternative keys for LOOPSTART, e.g. "LOOP_START".
+	// LOOPSTART is always matched.
+	LoopStartKeys []string
+
+	// LoopLengthKeys is the alternative keys for LOOPLENGTH.
+	// LOOPLENGTH is always matched.
+	LoopLengthKeys []string
+
+	// LoopEndKeys is the alternative keys for LOOPEND.
+	// LOOPEND is always matched.
+	LoopEndKeys []string
+
+	// TimeValues specifies whether the Decoder accepts the values of LOOPSTART, LOOPLENGTH and LOOPEND in
+	// seconds, e.g. "12.345", or in the form of [[hh:]mm:]ss[.fff], e.g. "00:01:23.500". Such values are
+	// converted to samples at the sample rate of the stream, rounded to the nearest sample. A value without
+	// a decimal point or a colon is still a number of samples.
+	//
+	// The Decoder returns an error for such values when the sample rate of the stream is unknown.
+	//
+	// The default (zero) value is false.
+	TimeValues bool
+
+	// Serial is the serial number of the logical stream the Decoder reads in a multiplexed stream, instead
+	// of the first Vorbis, Opus or FLAC stream. Serial is used only when HasSerial is true.
+	// When the stream does not have a Vorbis, Opus or FLAC stream with the serial number, the Decoder
+	// returns ErrNoVorbisStream.
+	//
+	// Serial applies to the functions reading one logical stream, e.g. Read, ReadInfo and BuildSeekTable,
+	// but not to ReadAll.
+	Serial uint32
+
+	// HasSerial specifies whether the Decoder uses Serial to choose the logical stream.
+	//
+	// The default (zero) value is false.
+	HasSerial bool
+}
+
+// Decoder reads meta data from an Ogg stream with options.
+//
+// A Decoder reads its source only once. Call only one of the Read methods.
+type Decoder struct {
+	src  io.Reader
+	opts DecoderOptions
+	tags *tagMatcher
+}
+
+// NewDecoder creates a new Decoder for src.
+//
+// If opts is nil, the default options are used.
+func NewDecoder(src io.Reader, opts *DecoderOptions) *Decoder {
+	d := &Decoder{
+		src: src,
+	}
+	if opts != nil {
+		d.opts = *opts
+	}
+	d.tags = newTagMatcher(&d.opts)
+	return d
+}
+
+func (d *Decoder) pageReader() *pageReader {
+	return newPageReader(d.src, &d.opts)
+}
+
+func (d *Decoder) pageReaderContext(ctx context.Context) *pageReader {
+	pr := d.pageReader()
+	pr.ctx = ctx
+	return pr
+}
+
+// Read returns LOOPSTART and LOOPLENGTH meta data values in the same way as the function Read.
+func (d *Decoder) Read() (loopStart, loopLength int64, err error) {
+	return readLoop(d.pageReader(), d.tags)
+}
+
+// ReadContext is like Read but stops reading and returns the context's error when ctx is done, in the
+// same way as the function ReadContext.
+func (d *Decoder) ReadContext(ctx context.Context) (loopStart, loopLength int64, err error) {
+	return readLoop(d.pageReaderContext(ctx), d.tags)
+}
+
+// ReadInfo returns the audio parameters and loop meta data in the same way as the function ReadInfo.
+func (d *Decoder) ReadInfo() (Info, error) {
+	return readInfo(d.pageReader(), d.tags)
+}
+
+// ReadInfoContext is like ReadInfo but stops reading and returns the context's error when ctx is done.
+// This is useful for a stream that is not an io.Seeker, as ReadInfo reads it to the end.
+func (d *Decoder) ReadInfoContext(ctx context.Context) (Info, error) {
+	return readInfo(d.pageReaderContext(ctx), d.tags)
+}
+
+// ReadComments returns all the comments in the same way as the function ReadComments.
+func (d *Decoder) ReadComments() (map[string][]string, error) {
+	return readComments(d.pageReader())
+}
+
+// ReadAll returns the Results of all the Vorbis, Opus and FLAC logical streams in the same way as the
+// function ReadAll.
+func (d *Decoder) ReadAll() ([]Result, error) {
+	return readAll(d.pageReader(), d.tags)
+}
+
+// ReadAllContext is like ReadAll but stops reading and returns the context's error when ctx is done.
+func (d *Decoder) ReadAllContext(ctx context.Context) ([]Result, error) {
+	return readAll(d.pageReaderContext(ctx), d.tags)
+}
+
+// BuildSeekTable returns the SeekTable of the first Vorbis, Opus or FLAC stream, or the stream of Serial,
+// in the same way as the function BuildSeekTable.
+func (d *Decoder) BuildSeekTable() (*SeekTable, error) {
+	return buildSeekTable(d.pageReader())
+}
+
+// ReadFLAC returns the audio parameters and loop meta data of a native FLAC stream in the same way as the
+// function ReadFLAC.
+func (d *Decoder) ReadFLAC() (Info, error) {
+	info, _, err := readFLAC(d.pageReader(), d.tags)
+	return info, err
+}
+
+// ReadMP3 returns the audio parameters and loop meta data of an MP3 stream in the same way as the
+// function ReadMP3.
+func (d *Decoder) ReadMP3() (Info, error) {
+	info, _, err := readMP3(d.pageReader(), d.tags)
+	return info, err
+}
+
+// ReadAny detects the format and returns the audio parameters and loop meta data in the same way as the
+// function ReadAny.
+func (d *Decoder) ReadAny() (Info, Format, error) {
+	return readAny(d.pageReader(), d.tags)
+}
+
+// ReadAnyContext is like ReadAny but stops reading and returns the context's error when ctx is done.
+func (d *Decoder) ReadAnyContext(ctx context.Context) (Info, Format, error) {
+	return readAny(d.pageReaderContext(ctx), d.tags)
+}
+
+// Probe returns a Report of the meta data of the stream in the same way as the function Probe.
+func (d *Decoder) Probe() (*Report, error) {
+	return probe(d.pageReader(), d.tags)
+}
+
+// ReadWAV returns the audio parameters and loop meta data of a RIFF/WAVE stream in the same way as the
+// function ReadWAV.
+func (d *Decoder) ReadWAV() (Info, error) {
+	return readWAV(d.pageReader())
+}
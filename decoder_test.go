@@ -0,0 +1,343 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testMultiplexed returns an Ogg stream multiplexing two Vorbis streams of the serial numbers 1 and 2.
+// The stream of the serial number 2 has twice as many samples.
+func testMultiplexed() []byte {
+	ident1, comment1 := testVorbisHeaders([]string{"LOOPSTART=1", "LOOPLENGTH=10"})
+	ident2, comment2 := testVorbisHeaders([]string{"LOOPSTART=2", "LOOPLENGTH=20"})
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident1))
+	buf.Write(testPage(headerTypeBOS, 0, 2, 0, ident2))
+	buf.Write(testPage(0, 0, 1, 1, comment1, []byte("\x05vorbis")))
+	buf.Write(testPage(0, 0, 2, 1, comment2, []byte("\x05vorbis")))
+	buf.Write(testPage(headerTypeEOS, 1000, 1, 2, make([]byte, 100)))
+	buf.Write(testPage(headerTypeEOS, 2000, 2, 2, make([]byte, 100)))
+	return buf.Bytes()
+}
+
+func TestDecoderSerial(t *testing.T) {
+	data := testMultiplexed()
+
+	testCases := []struct {
+		opts   *DecoderOptions
+		start  int64
+		length int64
+		total  int64
+	}{
+		{
+			opts:   nil,
+			start:  1,
+			length: 10,
+			total:  1000,
+		},
+		{
+			opts:   &DecoderOptions{Serial: 2, HasSerial: true},
+			start:  2,
+			length: 20,
+			total:  2000,
+		},
+		// Serial is ignored without HasSerial.
+		{
+			opts:   &DecoderOptions{Serial: 2},
+			start:  1,
+			length: 10,
+			total:  1000,
+		},
+	}
+	for _, tc := range testCases {
+		start, length, err := NewDecoder(bytes.NewReader(data), tc.opts).Read()
+		if err != nil {
+			t.Errorf("Read with %+v: %v", tc.opts, err)
+			continue
+		}
+		if start != tc.start || length != tc.length {
+			t.Errorf("Read with %+v: got (%d, %d), want (%d, %d)", tc.opts, start, length, tc.start, tc.length)
+		}
+
+		info, err := NewDecoder(bytes.NewReader(data), tc.opts).ReadInfo()
+		if err != nil {
+			t.Errorf("ReadInfo with %+v: %v", tc.opts, err)
+			continue
+		}
+		if info.TotalSamples != tc.total {
+			t.Errorf("ReadInfo with %+v: got TotalSamples %d, want %d", tc.opts, info.TotalSamples, tc.total)
+		}
+
+		table, err := NewDecoder(bytes.NewReader(data), tc.opts).BuildSeekTable()
+		if err != nil {
+			t.Errorf("BuildSeekTable with %+v: %v", tc.opts, err)
+			continue
+		}
+		if _, err := table.OffsetForSample(tc.total - 1); err != nil {
+			t.Errorf("OffsetForSample(%d) with %+v: %v", tc.total-1, tc.opts, err)
+		}
+		if _, err := table.OffsetForSample(tc.total); err == nil {
+			t.Errorf("OffsetForSample(%d) with %+v: got no error", tc.total, tc.opts)
+		}
+	}
+
+	if _, _, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{Serial: 3, HasSerial: true}).Read(); !errors.Is(err, ErrNoVorbisStream) {
+		t.Errorf("Read with an unknown serial number: got %v, want ErrNoVorbisStream", err)
+	}
+}
+
+func TestDecoderMaxBytes(t *testing.T) {
+	data := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 10)
+	_, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{MaxBytes: 500}).ReadInfo()
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("got %v, want ErrLimitExceeded", err)
+	}
+	// The message tells the configured limit, not the remaining bytes.
+	if got, want := err.Error(), "more than 500 bytes"; !strings.Contains(got, want) {
+		t.Errorf("got %q, want it to contain %q", got, want)
+	}
+}
+
+func TestDecoderMaxPages(t *testing.T) {
+	data := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 10)
+	d := NewDecoder(bytes.NewReader(data), &DecoderOptions{MaxPages: 5})
+	if _, _, err := d.Read(); err != nil {
+		t.Errorf("Read: %v", err)
+	}
+	d = NewDecoder(bytes.NewReader(data), &DecoderOptions{MaxPages: 5})
+	if _, err := d.ReadInfo(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("ReadInfo: got %v, want ErrLimitExceeded", err)
+	}
+	// The limit is inclusive.
+	d = NewDecoder(bytes.NewReader(data), &DecoderOptions{MaxPages: 12})
+	if _, err := d.ReadInfo(); err != nil {
+		t.Errorf("ReadInfo: %v", err)
+	}
+}
+
+func TestDecoderMaxCommentSize(t *testing.T) {
+	for _, large := range []bool{false, true} {
+		comments := []string{"LOOPSTART=1", "LOOPLENGTH=2"}
+		data := testVorbis(comments, 1)
+		if large {
+			// The comment header spans pages, so the limit is checked before the packet is complete.
+			// testVorbis cannot lay out a packet on several pages, so EditComments does it.
+			comments = append(comments, "DESCRIPTION="+strings.Repeat("x", 100000))
+			var buf bytes.Buffer
+			if err := EditComments(&buf, bytes.NewReader(data), func(c *Comments) {
+				c.Fields = comments
+			}); err != nil {
+				t.Fatal(err)
+			}
+			data = buf.Bytes()
+		}
+		_, comment := testVorbisHeaders(comments)
+
+		if _, _, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{MaxCommentSize: len(comment)}).Read(); err != nil {
+			t.Errorf("Read with the limit of the comment size: %v", err)
+		}
+		if _, _, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{MaxCommentSize: len(comment) - 1}).Read(); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("Read with a smaller limit: got %v, want ErrLimitExceeded", err)
+		}
+		if _, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{MaxCommentSize: len(comment) - 1}).ReadAll(); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("ReadAll with a smaller limit: got %v, want ErrLimitExceeded", err)
+		}
+	}
+}
+
+func TestDecoderCorruptPages(t *testing.T) {
+	const audioPages = 3
+	// Each audio page is 128 bytes: a 27-byte header, a segment and a 100-byte body.
+	const audioPageSize = 128
+	middlePage := func(data []byte) int {
+		return len(data) - 2*audioPageSize
+	}
+	stream := func() []byte {
+		return testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, audioPages)
+	}
+
+	// fakePage is a page with the capture pattern but a wrong checksum, which junk might have by chance.
+	fakePage := append([]byte("OggS"), make([]byte, 23)...)
+
+	testCases := []struct {
+		name string
+		data []byte
+		opts DecoderOptions
+		err  error
+	}{
+		{
+			name: "junk before the stream",
+			data: append([]byte("junk\x00Ogg"), stream()...),
+			err:  ErrNotOgg,
+		},
+		{
+			name: "junk before the stream with Resync",
+			data: append([]byte("junk\x00Ogg"), stream()...),
+			opts: DecoderOptions{Resync: true},
+		},
+		{
+			name: "junk with a capture pattern with Resync and VerifyCRC",
+			data: append(append([]byte("junk"), fakePage...), stream()...),
+			opts: DecoderOptions{Resync: true, VerifyCRC: true},
+		},
+		{
+			name: "damaged page header",
+			data: func() []byte {
+				b := stream()
+				b[middlePage(b)] = 'X'
+				return b
+			}(),
+			err: ErrCorruptPage,
+		},
+		{
+			name: "damaged page header with Resync",
+			data: func() []byte {
+				b := stream()
+				b[middlePage(b)] = 'X'
+				return b
+			}(),
+			opts: DecoderOptions{Resync: true},
+		},
+		{
+			name: "damaged page body",
+			data: func() []byte {
+				b := stream()
+				b[middlePage(b)+50] ^= 0xff
+				return b
+			}(),
+		},
+		{
+			name: "damaged page body with VerifyCRC",
+			data: func() []byte {
+				b := stream()
+				b[middlePage(b)+50] ^= 0xff
+				return b
+			}(),
+			opts: DecoderOptions{VerifyCRC: true},
+			err:  ErrCorruptPage,
+		},
+		{
+			name: "damaged page body with VerifyCRC and SkipCorruptPages",
+			data: func() []byte {
+				b := stream()
+				b[middlePage(b)+50] ^= 0xff
+				return b
+			}(),
+			opts: DecoderOptions{VerifyCRC: true, SkipCorruptPages: true},
+		},
+		{
+			name: "damaged page body with VerifyCRC and Resync",
+			data: func() []byte {
+				b := stream()
+				b[middlePage(b)+50] ^= 0xff
+				return b
+			}(),
+			opts: DecoderOptions{VerifyCRC: true, Resync: true},
+		},
+		{
+			// SkipCorruptPages does nothing without VerifyCRC.
+			name: "damaged page header with SkipCorruptPages",
+			data: func() []byte {
+				b := stream()
+				b[middlePage(b)] = 'X'
+				return b
+			}(),
+			opts: DecoderOptions{SkipCorruptPages: true},
+			err:  ErrCorruptPage,
+		},
+	}
+	for _, tc := range testCases {
+		for _, seekable := range []bool{true, false} {
+			var src io.Reader = bytes.NewReader(tc.data)
+			if !seekable {
+				src = struct{ io.Reader }{src}
+			}
+			opts := tc.opts
+			info, err := NewDecoder(src, &opts).ReadInfo()
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Errorf("%s (seekable: %t): got %v, want %v", tc.name, seekable, err, tc.err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s (seekable: %t): %v", tc.name, seekable, err)
+				continue
+			}
+			if info.LoopStart != 1 || info.LoopLength != 2 {
+				t.Errorf("%s (seekable: %t): got (%d, %d), want (1, 2)", tc.name, seekable, info.LoopStart, info.LoopLength)
+			}
+			if info.TotalSamples != audioPages*1000 {
+				t.Errorf("%s (seekable: %t): got TotalSamples %d, want %d", tc.name, seekable, info.TotalSamples, audioPages*1000)
+			}
+		}
+	}
+}
+
+// cancelReader reads one byte at a time from r, and calls cancel after n bytes are read.
+type cancelReader struct {
+	r      io.Reader
+	n      int
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		r.cancel()
+	}
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	n, err := r.r.Read(p)
+	r.n -= n
+	return n, err
+}
+
+func TestDecoderReadContext(t *testing.T) {
+	data := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 10)
+
+	start, length, err := ReadContext(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadContext: %v", err)
+	}
+	if start != 1 || length != 2 {
+		t.Errorf("ReadContext: got (%d, %d), want (1, 2)", start, length)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := ReadContext(ctx, bytes.NewReader(data)); err != context.Canceled {
+		t.Errorf("ReadContext with a canceled context: got %v, want %v", err, context.Canceled)
+	}
+
+	// The context is checked between reads in a page as well as between pages.
+	for _, n := range []int{10, len(testVorbis(nil, 0)) + 10} {
+		ctx, cancel := context.WithCancel(context.Background())
+		r := &cancelReader{r: bytes.NewReader(data), n: n, cancel: cancel}
+		if _, err := NewDecoder(r, nil).ReadInfoContext(ctx); err != context.Canceled {
+			t.Errorf("ReadInfoContext canceled after %d bytes: got %v, want %v", n, err, context.Canceled)
+		}
+		cancel()
+		if r.n > 0 {
+			t.Errorf("ReadInfoContext canceled after %d bytes: read only %d bytes", n, n-r.n)
+		}
+	}
+}
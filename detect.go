@@ -0,0 +1,186 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrUnknownFormat is returned when the format of the stream is none of the supported ones.
+var ErrUnknownFormat = errors.New("oggloop: unknown format")
+
+// Format represents a file format.
+type Format int
+
+const (
+	// FormatUnknown is an unknown format.
+	FormatUnknown Format = iota
+
+	// FormatOgg is Ogg, e.g. Ogg/Vorbis, Ogg/Opus or Ogg FLAC.
+	FormatOgg
+
+	// FormatFLAC is native FLAC.
+	FormatFLAC
+
+	// FormatWAV is RIFF/WAVE.
+	FormatWAV
+
+	// FormatMP3 is MP3, or MPEG audio in general.
+	FormatMP3
+)
+
+// String implements fmt.Stringer.
+func (f Format) String() string {
+	switch f {
+	case FormatOgg:
+		return "Ogg"
+	case FormatFLAC:
+		return "FLAC"
+	case FormatWAV:
+		return "WAV"
+	case FormatMP3:
+		return "MP3"
+	default:
+		return "unknown"
+	}
+}
+
+// Detect detects the format of the given src by its magic bytes, e.g. OggS and fLaC.
+// Detect returns ErrUnknownFormat when the format is none of the supported ones.
+//
+// Detect reads as many bytes of src as it needs. As an ID3v2 tag can precede both FLAC and MP3, Detect
+// reads the whole of an ID3v2 tag at the beginning of src.
+//
+// If src can seek, Detect seeks src back to the position where it started, so src can be read again from
+// there. Otherwise, Detect consumes src: the bytes it reads, and possibly more as the reads are buffered,
+// are lost. Use ReadAny to detect the format of such a src and then read the stream.
+func Detect(src io.Reader) (Format, error) {
+	pr := newPageReader(src, nil)
+	f, err := detect(pr)
+	if pr.seeker != nil {
+		if _, serr := pr.seeker.Seek(pr.start, io.SeekStart); serr != nil && err == nil {
+			return FormatUnknown, serr
+		}
+	}
+	return f, err
+}
+
+// detect detects the format, and pushes back the bytes it reads to pr.
+func detect(pr *pageReader) (Format, error) {
+	head, err := pr.readBytes(4)
+	if err == io.ErrUnexpectedEOF {
+		return FormatUnknown, ErrUnknownFormat
+	}
+	if err != nil {
+		return FormatUnknown, err
+	}
+	defer func() {
+		pr.unreadBytes(head)
+	}()
+
+	switch string(head) {
+	case "OggS":
+		return FormatOgg, nil
+	case "fLaC":
+		return FormatFLAC, nil
+	case "RIFF":
+		b, err := pr.readBytes(8)
+		if err == io.ErrUnexpectedEOF {
+			return FormatUnknown, ErrUnknownFormat
+		}
+		if err != nil {
+			return FormatUnknown, err
+		}
+		head = append(head, b...)
+		if string(b[4:]) == "WAVE" {
+			return FormatWAV, nil
+		}
+		return FormatUnknown, ErrUnknownFormat
+	}
+
+	if string(head[:3]) == "ID3" {
+		// Look at the bytes after the tag.
+		b, err := pr.readBytes(6)
+		if err == io.ErrUnexpectedEOF {
+			return FormatUnknown, ErrUnknownFormat
+		}
+		if err != nil {
+			return FormatUnknown, err
+		}
+		head = append(head, b...)
+		size := id3v2Size(b[2:6])
+		if b[1]&0x10 != 0 {
+			size += 10
+		}
+		if err := pr.checkCommentSize(int(size)); err != nil {
+			return FormatUnknown, err
+		}
+		b, err = pr.readBytes(int(size))
+		if err == io.ErrUnexpectedEOF {
+			return FormatUnknown, ErrUnknownFormat
+		}
+		if err != nil {
+			return FormatUnknown, err
+		}
+		head = append(head, b...)
+		b, err = pr.readBytes(4)
+		// An MP3 stream might have only the tag.
+		if err == io.ErrUnexpectedEOF {
+			return FormatMP3, nil
+		}
+		if err != nil {
+			return FormatUnknown, err
+		}
+		head = append(head, b...)
+		if string(b) == "fLaC" {
+			return FormatFLAC, nil
+		}
+		return FormatMP3, nil
+	}
+
+	if _, ok := parseMPEGFrameHeader(head); ok {
+		return FormatMP3, nil
+	}
+	return FormatUnknown, ErrUnknownFormat
+}
+
+// ReadAny detects the format of the given src, and reads it in the same way as ReadInfo, ReadFLAC, ReadWAV
+// or ReadMP3. ReadAny returns ErrUnknownFormat when the format is none of the supported ones.
+func ReadAny(src io.Reader) (Info, Format, error) {
+	return NewDecoder(src, nil).ReadAny()
+}
+
+func readAny(pr *pageReader, tags *tagMatcher) (Info, Format, error) {
+	f, err := detect(pr)
+	if err != nil {
+		return Info{}, f, err
+	}
+	var info Info
+	switch f {
+	case FormatOgg:
+		info, err = readInfo(pr, tags)
+	case FormatFLAC:
+		info, _, err = readFLAC(pr, tags)
+	case FormatWAV:
+		info, err = readWAV(pr)
+	case FormatMP3:
+		info, _, err = readMP3(pr, tags)
+	}
+	if err != nil {
+		return Info{}, f, err
+	}
+	return info, f, nil
+}
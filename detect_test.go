@@ -0,0 +1,92 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   []byte
+		format Format
+	}{
+		{
+			name:   "Ogg",
+			data:   testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 1),
+			format: FormatOgg,
+		},
+		{
+			name:   "FLAC",
+			data:   testFLAC([]string{"LOOPSTART=1", "LOOPLENGTH=2"}),
+			format: FormatFLAC,
+		},
+		{
+			name:   "FLAC after an ID3v2 tag",
+			data:   append(testID3v2(3, 0, testID3v2Frame(3, "TXXX", 0, testTXXX("A", "B"))), testFLAC([]string{"LOOPSTART=1", "LOOPLENGTH=2"})...),
+			format: FormatFLAC,
+		},
+		{
+			name:   "WAV",
+			data:   testWAV([][2]uint32{{1, 2}}),
+			format: FormatWAV,
+		},
+		{
+			name:   "MP3",
+			data:   append(testID3v2(3, 0, testID3v2Frame(3, "TXXX", 0, testTXXX("LOOPSTART", "1"))), testMPEGFrame("", -1, 0, 0)...),
+			format: FormatMP3,
+		},
+	}
+	for _, tc := range testCases {
+		// Start in the middle of the source to check that Detect seeks back to the start position.
+		const offset = 3
+		r := bytes.NewReader(append(make([]byte, offset), tc.data...))
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		f, err := Detect(r)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if f != tc.format {
+			t.Errorf("%s: got %v, want %v", tc.name, f, tc.format)
+		}
+		if pos, _ := r.Seek(0, io.SeekCurrent); pos != offset {
+			t.Errorf("%s: got position %d after Detect, want %d", tc.name, pos, offset)
+		}
+		if _, f, err := ReadAny(r); err != nil || f != tc.format {
+			t.Errorf("%s: ReadAny after Detect: got (%v, %v), want (%v, nil)", tc.name, f, err, tc.format)
+		}
+
+		// A plain reader is consumed.
+		pr := struct{ io.Reader }{bytes.NewReader(tc.data)}
+		f, err = Detect(pr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if f != tc.format {
+			t.Errorf("%s: got %v, want %v", tc.name, f, tc.format)
+		}
+	}
+
+	if _, err := Detect(bytes.NewReader([]byte("RIFF"))); err != ErrUnknownFormat {
+		t.Errorf("got %v, want %v", err, ErrUnknownFormat)
+	}
+}
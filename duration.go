@@ -0,0 +1,45 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"time"
+)
+
+// SampleDuration returns the time at the given sample position at the sample rate.
+// SampleDuration returns 0 when the sample rate is unknown.
+func (i Info) SampleDuration(sample int64) time.Duration {
+	if i.SampleRate <= 0 {
+		return 0
+	}
+	// Divide first to avoid overflow for large positions.
+	r := int64(i.SampleRate)
+	return time.Duration(sample/r)*time.Second + time.Duration(sample%r)*time.Second/time.Duration(r)
+}
+
+// LoopStartDuration returns the time at LOOPSTART.
+func (i Info) LoopStartDuration() time.Duration {
+	return i.SampleDuration(i.LoopStart)
+}
+
+// LoopDuration returns the length of the loop region in time.
+func (i Info) LoopDuration() time.Duration {
+	return i.SampleDuration(i.LoopLength)
+}
+
+// TotalDuration returns the length of the stream in time.
+func (i Info) TotalDuration() time.Duration {
+	return i.SampleDuration(i.TotalSamples)
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampleDuration(t *testing.T) {
+	testCases := []struct {
+		sampleRate int
+		sample     int64
+		want       time.Duration
+	}{
+		{sampleRate: 44100, sample: 0, want: 0},
+		{sampleRate: 44100, sample: 44100, want: time.Second},
+		{sampleRate: 44100, sample: 22050, want: 500 * time.Millisecond},
+		// The fraction of a nanosecond is truncated.
+		{sampleRate: 44100, sample: 1, want: 22675 * time.Nanosecond},
+		// A day and a sample.
+		{sampleRate: 48000, sample: 48000*86400 + 1, want: 24*time.Hour + 20833*time.Nanosecond},
+		{sampleRate: 0, sample: 44100, want: 0},
+	}
+	for _, tc := range testCases {
+		info := Info{SampleRate: tc.sampleRate}
+		if got := info.SampleDuration(tc.sample); got != tc.want {
+			t.Errorf("SampleDuration(%d) at %d Hz: got %v, want %v", tc.sample, tc.sampleRate, got, tc.want)
+		}
+	}
+
+	info := Info{
+		SampleRate:   48000,
+		TotalSamples: 48000 * 90,
+		LoopStart:    24000,
+		LoopLength:   48000 * 60,
+	}
+	if got, want := info.LoopStartDuration(), 500*time.Millisecond; got != want {
+		t.Errorf("LoopStartDuration: got %v, want %v", got, want)
+	}
+	if got, want := info.LoopDuration(), time.Minute; got != want {
+		t.Errorf("LoopDuration: got %v, want %v", got, want)
+	}
+	if got, want := info.TotalDuration(), 90*time.Second; got != want {
+		t.Errorf("TotalDuration: got %v, want %v", got, want)
+	}
+}
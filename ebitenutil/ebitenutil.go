@@ -0,0 +1,74 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ebitenutil provides utilities to play Ogg/Vorbis files with loop tags in Ebitengine.
+//
+// This package is a separate module so that the oggloop package does not depend on Ebitengine.
+package ebitenutil
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+
+	"github.com/hajimehoshi/oggloop"
+)
+
+// bytesPerFrame is the number of bytes per sample frame of a stream decoded by audio/vorbis, which is
+// always 16-bit stereo.
+const bytesPerFrame = 4
+
+// NewInfiniteLoop reads the loop tags of the given Ogg/Vorbis src, decodes src with audio/vorbis at
+// sampleRate, and returns a stream that plays the intro before LOOPSTART and then repeats the loop region
+// forever. sampleRate is usually the sample rate of the audio context.
+//
+//	stream, err := ebitenutil.NewInfiniteLoop(bytes.NewReader(oggBytes), audioContext.SampleRate())
+//	...
+//	player, err := audioContext.NewPlayer(stream)
+//
+// When the loop has neither LOOPLENGTH nor LOOPEND, the loop region extends to the end of the stream. When
+// src does not have loop tags at all, the whole stream is repeated.
+//
+// src must be positioned at the beginning of the Ogg stream. The returned stream reads src, so src must not
+// be used after NewInfiniteLoop returns.
+func NewInfiniteLoop(src io.ReadSeeker, sampleRate int) (*audio.InfiniteLoop, error) {
+	start, err := src.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	info, err := oggloop.ReadInfo(src)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := src.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	introLength, loopLength, err := info.InfiniteLoopBytes(sampleRate, bytesPerFrame)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := vorbis.DecodeWithSampleRate(sampleRate, src)
+	if err != nil {
+		return nil, err
+	}
+	return audio.NewInfiniteLoopWithIntro(stream, introLength, loopLength), nil
+}
+
+// NewInfiniteLoopFromBytes is like NewInfiniteLoop but takes the bytes of an Ogg/Vorbis file.
+func NewInfiniteLoopFromBytes(src []byte, sampleRate int) (*audio.InfiniteLoop, error) {
+	return NewInfiniteLoop(bytes.NewReader(src), sampleRate)
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebitenutil_test
+
+import (
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+
+	"github.com/hajimehoshi/oggloop/ebitenutil"
+)
+
+func ExampleNewInfiniteLoop() {
+	audioContext := audio.NewContext(48000)
+
+	f, err := os.Open("bgm.ogg")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	stream, err := ebitenutil.NewInfiniteLoop(f, audioContext.SampleRate())
+	if err != nil {
+		panic(err)
+	}
+	player, err := audioContext.NewPlayer(stream)
+	if err != nil {
+		panic(err)
+	}
+	player.Play()
+}
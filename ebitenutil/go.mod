@@ -0,0 +1,16 @@
+module github.com/hajimehoshi/oggloop/ebitenutil
+
+go 1.18
+
+require (
+	github.com/hajimehoshi/ebiten/v2 v2.6.7
+	github.com/hajimehoshi/oggloop v0.0.0-20261014060720-fbcff8b963f1
+)
+
+require (
+	github.com/ebitengine/oto/v3 v3.1.0 // indirect
+	github.com/ebitengine/purego v0.6.0 // indirect
+	github.com/jfreymuth/oggvorbis v1.0.5 // indirect
+	github.com/jfreymuth/vorbis v1.0.2 // indirect
+	golang.org/x/sys v0.12.0 // indirect
+)
@@ -12,11 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+//go:build example
 // +build example
 
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -26,6 +28,10 @@ import (
 
 func main() {
 	start, length, err := oggloop.Read(os.Stdin)
+	if errors.Is(err, oggloop.ErrNoLoopTags) {
+		fmt.Println("no loop tags")
+		return
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,51 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+)
+
+// ReadFile reads the named file and returns LOOPSTART and LOOPLENGTH meta data values in the same way as
+// Read.
+func ReadFile(name string) (loopStart, loopLength int64, err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// ReadBytes reads the given bytes and returns LOOPSTART and LOOPLENGTH meta data values in the same way as
+// Read.
+//
+// ReadBytes copies only the header pages it needs, and skips the other pages without copying them.
+func ReadBytes(b []byte) (loopStart, loopLength int64, err error) {
+	return Read(bytes.NewReader(b))
+}
+
+// ReadFS reads the named file in fsys, e.g. an embed.FS, and returns LOOPSTART and LOOPLENGTH meta data
+// values in the same way as Read.
+func ReadFS(fsys fs.FS, name string) (loopStart, loopLength int64, err error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	return Read(f)
+}
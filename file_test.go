@@ -0,0 +1,60 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadFile(t *testing.T) {
+	data := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 1)
+	name := filepath.Join(t.TempDir(), "test.ogg")
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"dir/test.ogg": &fstest.MapFile{Data: data},
+	}
+
+	for _, read := range []struct {
+		name string
+		f    func() (int64, int64, error)
+	}{
+		{name: "ReadFile", f: func() (int64, int64, error) { return ReadFile(name) }},
+		{name: "ReadBytes", f: func() (int64, int64, error) { return ReadBytes(data) }},
+		{name: "ReadFS", f: func() (int64, int64, error) { return ReadFS(fsys, "dir/test.ogg") }},
+	} {
+		start, length, err := read.f()
+		if err != nil {
+			t.Errorf("%s: %v", read.name, err)
+			continue
+		}
+		if start != 1 || length != 2 {
+			t.Errorf("%s: got (%d, %d), want (1, 2)", read.name, start, length)
+		}
+	}
+
+	if _, _, err := ReadFile(filepath.Join(t.TempDir(), "missing.ogg")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile: got %v, want fs.ErrNotExist", err)
+	}
+	if _, _, err := ReadFS(fsys, "missing.ogg"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFS: got %v, want fs.ErrNotExist", err)
+	}
+}
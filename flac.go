@@ -0,0 +1,159 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFLAC is returned when the stream is not a FLAC stream.
+var ErrNotFLAC = errors.New("oggloop: not a FLAC stream")
+
+// Metadata block types of FLAC.
+// https://www.rfc-editor.org/rfc/rfc9639#section-8
+const (
+	flacBlockTypeStreamInfo    = 0
+	flacBlockTypeVorbisComment = 4
+)
+
+// ReadFLAC reads the given src as a native FLAC stream and returns the audio parameters and the loop meta
+// data values in the VORBIS_COMMENT metadata block, in the same way as ReadInfo.
+//
+// ReadFLAC returns ErrNotFLAC when src is not a FLAC stream, and ErrNoCommentHeader when the stream does
+// not have a VORBIS_COMMENT metadata block. An ID3v2 tag before the FLAC stream is skipped.
+//
+// TotalSamples is 0 when the STREAMINFO metadata block does not tell the number of samples.
+// ReadFLAC stops reading right after the metadata blocks it needs.
+func ReadFLAC(src io.Reader) (Info, error) {
+	return NewDecoder(src, nil).ReadFLAC()
+}
+
+// readFLAC also returns the data of the VORBIS_COMMENT metadata block.
+func readFLAC(pr *pageReader, tags *tagMatcher) (Info, []byte, error) {
+	sig, err := pr.readBytes(4)
+	if err == io.ErrUnexpectedEOF {
+		return Info{}, nil, ErrNotFLAC
+	}
+	if err != nil {
+		return Info{}, nil, err
+	}
+	if string(sig[:3]) == "ID3" {
+		if err := skipID3v2(pr, sig); err != nil {
+			return Info{}, nil, err
+		}
+		sig, err = pr.readBytes(4)
+		if err == io.ErrUnexpectedEOF {
+			return Info{}, nil, ErrNotFLAC
+		}
+		if err != nil {
+			return Info{}, nil, err
+		}
+	}
+	if string(sig) != "fLaC" {
+		return Info{}, nil, ErrNotFLAC
+	}
+
+	var (
+		info       Info
+		streamInfo bool
+		comment    []byte
+	)
+	for !streamInfo || comment == nil {
+		h, err := pr.readBytes(4)
+		if err != nil {
+			return Info{}, nil, err
+		}
+		last := h[0]&0x80 != 0
+		blockType := h[0] & 0x7f
+		size := int(h[1])<<16 | int(h[2])<<8 | int(h[3])
+
+		switch blockType {
+		case flacBlockTypeStreamInfo:
+			if size < 34 {
+				return Info{}, nil, fmt.Errorf("%w: broken STREAMINFO block", ErrCorruptPage)
+			}
+			b, err := pr.readBytes(size)
+			if err != nil {
+				return Info{}, nil, err
+			}
+			info.SampleRate, info.Channels, info.TotalSamples = parseFLACStreamInfo(b)
+			streamInfo = true
+		case flacBlockTypeVorbisComment:
+			if err := pr.checkCommentSize(size); err != nil {
+				return Info{}, nil, err
+			}
+			b, err := pr.readBytes(size)
+			if err != nil {
+				return Info{}, nil, err
+			}
+			comment = b
+		default:
+			if err := pr.skipBytes(int64(size)); err != nil {
+				return Info{}, nil, err
+			}
+		}
+		if last {
+			break
+		}
+	}
+
+	// STREAMINFO must be the first metadata block.
+	if !streamInfo {
+		return Info{}, nil, fmt.Errorf("%w: no STREAMINFO block", ErrCorruptPage)
+	}
+	if comment == nil {
+		return Info{}, nil, ErrNoCommentHeader
+	}
+
+	vc, err := parseComment(comment)
+	if err != nil {
+		return Info{}, nil, err
+	}
+	loops, err := tags.loopTags(vc.Fields, info.SampleRate)
+	if err != nil {
+		return Info{}, nil, err
+	}
+	info.setLoops(loops)
+	info.LoopCount, info.LoopFade = tags.auxTags(vc.Fields)
+	return info, comment, nil
+}
+
+// skipID3v2 skips an ID3v2 tag whose first 4 bytes are already read as head.
+// https://id3.org/id3v2.4.0-structure
+func skipID3v2(pr *pageReader, head []byte) error {
+	rest, err := pr.readBytes(6)
+	if err != nil {
+		return err
+	}
+	h := append(head[:4:4], rest...)
+	size := id3v2Size(h[6:10])
+	// A footer follows the tag when the footer flag is set.
+	if h[5]&0x10 != 0 {
+		size += 10
+	}
+	return pr.skipBytes(size)
+}
+
+// parseFLACStreamInfo parses the data of a STREAMINFO metadata block. The data must be at least 34 bytes.
+// https://www.rfc-editor.org/rfc/rfc9639#section-8.2
+func parseFLACStreamInfo(data []byte) (sampleRate, channels int, totalSamples int64) {
+	sampleRate = int(data[10])<<12 | int(data[11])<<4 | int(data[12])>>4
+	channels = int(data[12]>>1&0x07) + 1
+	totalSamples = int64(data[13]&0x0f)<<32 | int64(binary.BigEndian.Uint32(data[14:18]))
+	return sampleRate, channels, totalSamples
+}
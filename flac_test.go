@@ -0,0 +1,195 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// testFLAC returns a native FLAC stream of 123456 samples with the given comments.
+func testFLAC(comments []string) []byte {
+	streamInfo := make([]byte, 34)
+	// 44100 Hz, 2 channels and 16 bits per sample.
+	streamInfo[10] = 0x0a
+	streamInfo[11] = 0xc4
+	streamInfo[12] = 0x42
+	streamInfo[13] = 0xf0
+	streamInfo[15] = 0x01
+	streamInfo[16] = 0xe2
+	streamInfo[17] = 0x40
+
+	vc := &vorbisComment{
+		Comments: Comments{
+			Vendor: "test",
+			Fields: comments,
+		},
+	}
+	comment := vc.bytes()
+
+	b := []byte("fLaC")
+	b = append(b, flacBlockTypeStreamInfo, 0, 0, byte(len(streamInfo)))
+	b = append(b, streamInfo...)
+	b = append(b, 0x80|flacBlockTypeVorbisComment, byte(len(comment)>>16), byte(len(comment)>>8), byte(len(comment)))
+	b = append(b, comment...)
+	return b
+}
+
+// testFLACBlock returns a FLAC metadata block of the given type.
+func testFLACBlock(blockType byte, last bool, data []byte) []byte {
+	if last {
+		blockType |= 0x80
+	}
+	return append([]byte{blockType, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))}, data...)
+}
+
+func TestReadFLAC(t *testing.T) {
+	flac := testFLAC([]string{"LOOPSTART=1000", "LOOPEND=5000"})
+	streamInfo := flac[8 : 8+34]
+	comment := (&vorbisComment{Comments: Comments{Fields: []string{"LOOPSTART=1", "LOOPLENGTH=2"}}}).bytes()
+	id3 := testID3v2(3, 0, testID3v2Frame(3, "TXXX", 0, testTXXX("LOOPSTART", "7")))
+
+	testCases := []struct {
+		name   string
+		data   []byte
+		start  int64
+		length int64
+		total  int64
+		err    error
+	}{
+		{
+			name:   "STREAMINFO and VORBIS_COMMENT",
+			data:   flac,
+			start:  1000,
+			length: 4000,
+			total:  123456,
+		},
+		{
+			// The tags of the ID3v2 tag are not used.
+			name:   "ID3v2 tag before the stream",
+			data:   append(append([]byte{}, id3...), flac...),
+			start:  1000,
+			length: 4000,
+			total:  123456,
+		},
+		{
+			name: "other blocks before VORBIS_COMMENT",
+			data: bytes.Join([][]byte{
+				[]byte("fLaC"),
+				testFLACBlock(flacBlockTypeStreamInfo, false, streamInfo),
+				// PADDING and APPLICATION.
+				testFLACBlock(1, false, make([]byte, 100)),
+				testFLACBlock(2, false, []byte("testdata")),
+				testFLACBlock(flacBlockTypeVorbisComment, true, comment),
+			}, nil),
+			start:  1,
+			length: 2,
+			total:  123456,
+		},
+		{
+			name: "no VORBIS_COMMENT",
+			data: bytes.Join([][]byte{
+				[]byte("fLaC"),
+				testFLACBlock(flacBlockTypeStreamInfo, false, streamInfo),
+				testFLACBlock(1, true, make([]byte, 100)),
+			}, nil),
+			err: ErrNoCommentHeader,
+		},
+		{
+			name: "no STREAMINFO",
+			data: bytes.Join([][]byte{
+				[]byte("fLaC"),
+				testFLACBlock(flacBlockTypeVorbisComment, true, comment),
+			}, nil),
+			err: ErrCorruptPage,
+		},
+		{
+			name: "short STREAMINFO",
+			data: bytes.Join([][]byte{
+				[]byte("fLaC"),
+				testFLACBlock(flacBlockTypeStreamInfo, false, streamInfo[:33]),
+				testFLACBlock(flacBlockTypeVorbisComment, true, comment),
+			}, nil),
+			err: ErrCorruptPage,
+		},
+		{
+			name: "not FLAC",
+			data: testVorbis(nil, 1),
+			err:  ErrNotFLAC,
+		},
+		{
+			name: "ID3v2 tag only",
+			data: id3,
+			err:  ErrNotFLAC,
+		},
+	}
+	for _, tc := range testCases {
+		info, err := ReadFLAC(bytes.NewReader(tc.data))
+		if tc.err != nil {
+			if !errors.Is(err, tc.err) {
+				t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if info.SampleRate != 44100 || info.Channels != 2 || info.TotalSamples != tc.total {
+			t.Errorf("%s: got %d Hz, %d channels and %d samples, want 44100 Hz, 2 channels and %d samples", tc.name, info.SampleRate, info.Channels, info.TotalSamples, tc.total)
+		}
+		if info.LoopStart != tc.start || info.LoopLength != tc.length {
+			t.Errorf("%s: got (%d, %d), want (%d, %d)", tc.name, info.LoopStart, info.LoopLength, tc.start, tc.length)
+		}
+	}
+}
+
+func TestReadOggFLAC(t *testing.T) {
+	data := testOggFLAC([]string{"LOOPSTART=4096", "LOOPLENGTH=8192"}, 3)
+	for _, src := range []io.Reader{bytes.NewReader(data), struct{ io.Reader }{bytes.NewReader(data)}} {
+		info, err := ReadInfo(src)
+		if err != nil {
+			t.Errorf("ReadInfo(%T): %v", src, err)
+			continue
+		}
+		if info.SampleRate != 44100 || info.Channels != 2 || info.TotalSamples != 3*4096 {
+			t.Errorf("ReadInfo(%T): got %d Hz, %d channels and %d samples, want 44100 Hz, 2 channels and %d samples", src, info.SampleRate, info.Channels, info.TotalSamples, 3*4096)
+		}
+		if info.LoopStart != 4096 || info.LoopLength != 8192 {
+			t.Errorf("ReadInfo(%T): got (%d, %d), want (4096, 8192)", src, info.LoopStart, info.LoopLength)
+		}
+	}
+
+	// The comment header must be the first packet after the identification header.
+	ident := data[27+1 : 27+1+51]
+	comment := testFLACBlock(flacBlockTypeVorbisComment, true, (&vorbisComment{Comments: Comments{Fields: []string{"LOOPSTART=1"}}}).bytes())
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident))
+	buf.Write(testPage(0, 0, 1, 1, testFLACBlock(1, false, make([]byte, 10))))
+	buf.Write(testPage(headerTypeEOS, 0, 1, 2, comment))
+	if _, _, err := Read(bytes.NewReader(buf.Bytes())); err != ErrNoCommentHeader {
+		t.Errorf("got %v, want %v", err, ErrNoCommentHeader)
+	}
+
+	// The identification header is too short for STREAMINFO.
+	buf.Reset()
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident[:20]))
+	buf.Write(testPage(headerTypeEOS, 0, 1, 1, comment))
+	if _, err := ReadInfo(bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrCorruptPage) {
+		t.Errorf("got %v, want ErrCorruptPage", err)
+	}
+}
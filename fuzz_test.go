@@ -0,0 +1,77 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func addFuzzSeeds(f *testing.F) {
+	ogg := testVorbis([]string{"LOOPSTART=1000", "LOOPLENGTH=5000", "TITLE=x"}, 3)
+	f.Add(ogg)
+	f.Add(ogg[:len(ogg)/2])
+	f.Add(testVorbis([]string{"LOOPSTART=1", "LOOPSTART=2", "LOOPEND=3", "LOOPSTART1=10", "LOOPLENGTH1=5"}, 1))
+	f.Add(testVorbis([]string{"LOOPSTART=00:01.5", "LOOPLENGTH=1.25", "LOOPCOUNT=2", "LOOPFADE=3.5"}, 1))
+	f.Add(append(testVorbis([]string{"LOOPSTART=1"}, 1), testVorbis([]string{"LOOPSTART=2"}, 2)...))
+	f.Add(testOpus([]string{"LOOPSTART=1000", "LOOPLENGTH=5000"}, 2))
+	f.Add(testOggFLAC([]string{"LOOPSTART=1000", "LOOPLENGTH=5000"}, 2))
+}
+
+// FuzzRead checks that the Ogg parsers do not panic on any input.
+func FuzzRead(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _, _ = Read(bytes.NewReader(data))
+		// A reader that is not an io.Seeker takes the other code paths for skipping bytes.
+		_, _, _ = Read(struct{ io.Reader }{bytes.NewReader(data)})
+		_, _ = ReadInfo(bytes.NewReader(data))
+		_, _ = ReadAll(bytes.NewReader(data))
+		_, _ = ReadComments(bytes.NewReader(data))
+
+		var buf bytes.Buffer
+		if err := SetLoop(&buf, bytes.NewReader(data), 12, 34); err == nil {
+			start, length, err := Read(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("Read after SetLoop: %v", err)
+			}
+			if start != 12 || length != 34 {
+				t.Fatalf("Read after SetLoop: got (%d, %d), want (12, 34)", start, length)
+			}
+		}
+
+		r := NewPacketReader(bytes.NewReader(data), nil)
+		for {
+			if _, err := r.NextPacket(); err != nil {
+				break
+			}
+		}
+	})
+}
+
+// FuzzReadAny checks that the parsers of all the formats do not panic on any input.
+func FuzzReadAny(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Add(testFLAC([]string{"LOOPSTART=1000", "LOOPLENGTH=5000"}))
+	f.Add(append([]byte("ID3\x04\x00\x00\x00\x00\x00\x00"), testFLAC(nil)...))
+	f.Add(testWAV([][2]uint32{{100, 899}, {200, 299}}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = Detect(bytes.NewReader(data))
+		_, _, _ = ReadAny(bytes.NewReader(data))
+		_, _, _ = ReadAny(struct{ io.Reader }{bytes.NewReader(data)})
+		_, _ = Probe(bytes.NewReader(data))
+	})
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// bytesPerSample is the size of a 16-bit PCM sample in bytes.
+const bytesPerSample = 2
+
+type loopReader struct {
+	src io.ReadSeeker
+
+	// loopStart and loopEnd are the byte offsets of the loop region in src.
+	loopStart int64
+	loopEnd   int64
+
+	// pos is the current position in the infinite stream.
+	pos int64
+
+	// srcPos is the current position of src, or -1 when it is unknown.
+	srcPos int64
+
+	err error
+}
+
+// NewLoopReader returns an io.ReadSeeker that reads the decoded PCM from the beginning, and then repeats
+// the loop region forever. The part before the loop region, the intro, is read only once.
+//
+// decoded must be 16-bit signed little-endian interleaved PCM with the given number of channels at
+// sampleRate, like a decoded stream of Ebiten's audio packages. loopStart and loopLength are in samples
+// per channel, e.g. the values Read returns. If loopLength is 0, the loop region extends to the end of
+// decoded.
+//
+// The returned reader never ends, and does not support io.SeekEnd. When the arguments are invalid, the
+// returned reader's Read and Seek return an error.
+func NewLoopReader(decoded io.ReadSeeker, sampleRate, channels int, loopStart, loopLength int64) io.ReadSeeker {
+	l := &loopReader{
+		src:    decoded,
+		srcPos: -1,
+	}
+	switch {
+	case sampleRate <= 0:
+		l.err = fmt.Errorf("oggloop: sample rate must be positive: %d", sampleRate)
+		return l
+	case channels <= 0:
+		l.err = fmt.Errorf("oggloop: channels must be positive: %d", channels)
+		return l
+	case loopStart < 0:
+		l.err = fmt.Errorf("oggloop: LOOPSTART must be non-negative: %d", loopStart)
+		return l
+	case loopLength < 0:
+		l.err = fmt.Errorf("oggloop: LOOPLENGTH must be non-negative: %d", loopLength)
+		return l
+	}
+
+	frameSize := int64(channels) * bytesPerSample
+	if loopLength > math.MaxInt64/frameSize-loopStart {
+		l.err = fmt.Errorf("oggloop: LOOPSTART %d plus LOOPLENGTH %d is too large", loopStart, loopLength)
+		return l
+	}
+	l.loopStart = loopStart * frameSize
+	if loopLength > 0 {
+		l.loopEnd = (loopStart + loopLength) * frameSize
+	} else {
+		end, err := decoded.Seek(0, io.SeekEnd)
+		if err != nil {
+			l.err = err
+			return l
+		}
+		l.loopEnd = end - end%frameSize
+	}
+	if l.loopEnd <= l.loopStart {
+		l.err = fmt.Errorf("oggloop: empty loop region: LOOPSTART %d is at or after the end of the stream", loopStart)
+	}
+	return l
+}
+
+// srcOffset returns the position in src for the position pos in the infinite stream.
+func (l *loopReader) srcOffset(pos int64) int64 {
+	if pos < l.loopEnd {
+		return pos
+	}
+	return l.loopStart + (pos-l.loopStart)%(l.loopEnd-l.loopStart)
+}
+
+// Read implements io.Reader.
+func (l *loopReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	offset := l.srcOffset(l.pos)
+	// Do not read across the end of the loop region, so that the next read starts from the loop start.
+	if n := l.loopEnd - offset; int64(len(p)) > n {
+		p = p[:n]
+	}
+	if offset != l.srcPos {
+		if _, err := l.src.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+		l.srcPos = offset
+	}
+
+	n, err := l.src.Read(p)
+	l.srcPos += int64(n)
+	l.pos += int64(n)
+	if err == io.EOF {
+		if n > 0 {
+			return n, nil
+		}
+		return 0, fmt.Errorf("oggloop: the decoded stream ends at %d before the end of the loop region %d: %w", offset, l.loopEnd, io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (l *loopReader) Seek(offset int64, whence int) (int64, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += l.pos
+	case io.SeekEnd:
+		return 0, errors.New("oggloop: io.SeekEnd is not supported for an infinite stream")
+	default:
+		return 0, fmt.Errorf("oggloop: invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("oggloop: negative position: %d", offset)
+	}
+	l.pos = offset
+	return offset, nil
+}
+
+// InfiniteLoopBytes returns the byte lengths of the intro and the loop region of the primary loop for a
+// decoded stream at sampleRate with bytesPerFrame bytes per sample frame, e.g. 4 for 16-bit stereo PCM.
+// The values are for Ebiten's audio.NewInfiniteLoopWithIntro:
+//
+//	info, err := oggloop.ReadInfo(bytes.NewReader(oggBytes))
+//	...
+//	stream, err := vorbis.DecodeWithSampleRate(sampleRate, bytes.NewReader(oggBytes))
+//	...
+//	intro, loop, err := info.InfiniteLoopBytes(sampleRate, 4)
+//	...
+//	player, err := audioContext.NewPlayer(audio.NewInfiniteLoopWithIntro(stream, intro, loop))
+//
+// The positions are converted from the sample rate of the stream to sampleRate, as a decoder resamples the
+// stream to the sample rate of the audio context. When the loop has neither LOOPLENGTH nor LOOPEND, the
+// loop region extends to the end of the stream.
+//
+// InfiniteLoopBytes returns an error when the arguments are invalid, or when the loop region is empty.
+func (i Info) InfiniteLoopBytes(sampleRate, bytesPerFrame int) (introLength, loopLength int64, err error) {
+	switch {
+	case sampleRate <= 0:
+		return 0, 0, fmt.Errorf("oggloop: sample rate must be positive: %d", sampleRate)
+	case bytesPerFrame <= 0:
+		return 0, 0, fmt.Errorf("oggloop: bytes per frame must be positive: %d", bytesPerFrame)
+	case i.SampleRate <= 0:
+		return 0, 0, fmt.Errorf("oggloop: the sample rate of the stream is unknown")
+	}
+
+	end := i.LoopStart + i.LoopLength
+	if i.LengthTag == LengthTagNone {
+		end = i.TotalSamples
+	}
+	if end <= i.LoopStart {
+		return 0, 0, fmt.Errorf("oggloop: empty loop region: LOOPSTART %d, end %d", i.LoopStart, end)
+	}
+
+	// Convert the positions rather than the length to avoid accumulating rounding errors.
+	frameSize := int64(bytesPerFrame)
+	startBytes, ok := i.resampledBytes(i.LoopStart, sampleRate, frameSize)
+	if !ok {
+		return 0, 0, fmt.Errorf("oggloop: LOOPSTART %d is too large", i.LoopStart)
+	}
+	endBytes, ok := i.resampledBytes(end, sampleRate, frameSize)
+	if !ok {
+		return 0, 0, fmt.Errorf("oggloop: the end of the loop region %d is too large", end)
+	}
+	return startBytes, endBytes - startBytes, nil
+}
+
+// resampledBytes returns the byte offset of the given sample position converted to sampleRate.
+// resampledBytes returns false when the offset overflows.
+func (i Info) resampledBytes(sample int64, sampleRate int, frameSize int64) (int64, bool) {
+	from, to := int64(i.SampleRate), int64(sampleRate)
+	// Divide first to avoid overflow for large positions.
+	q, r := sample/from, sample%from
+	if q >= math.MaxInt64/to/frameSize {
+		return 0, false
+	}
+	return (q*to + r*to/from) * frameSize, true
+}
@@ -0,0 +1,257 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// testPCM returns 16-bit mono PCM of the given number of samples, where each sample is its index.
+func testPCM(samples int) []byte {
+	b := make([]byte, samples*2)
+	for i := 0; i < samples; i++ {
+		binary.LittleEndian.PutUint16(b[2*i:], uint16(i))
+	}
+	return b
+}
+
+// readSamples reads n 16-bit mono samples from r.
+func readSamples(r io.Reader, n int) ([]int, error) {
+	b := make([]byte, n*2)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	s := make([]int, n)
+	for i := range s {
+		s[i] = int(binary.LittleEndian.Uint16(b[2*i:]))
+	}
+	return s, nil
+}
+
+func TestLoopReader(t *testing.T) {
+	testCases := []struct {
+		name       string
+		samples    int
+		loopStart  int64
+		loopLength int64
+		// want returns the sample at the given position of the infinite stream.
+		want func(pos int) int
+	}{
+		{
+			name:       "loop in the middle",
+			samples:    10,
+			loopStart:  2,
+			loopLength: 5,
+			want: func(pos int) int {
+				if pos < 7 {
+					return pos
+				}
+				return 2 + (pos-2)%5
+			},
+		},
+		{
+			name:       "loop to the end",
+			samples:    10,
+			loopStart:  2,
+			loopLength: 0,
+			want: func(pos int) int {
+				if pos < 10 {
+					return pos
+				}
+				return 2 + (pos-2)%8
+			},
+		},
+		{
+			name:       "no intro",
+			samples:    10,
+			loopStart:  0,
+			loopLength: 3,
+			want: func(pos int) int {
+				return pos % 3
+			},
+		},
+	}
+	for _, tc := range testCases {
+		r := NewLoopReader(bytes.NewReader(testPCM(tc.samples)), 44100, 1, tc.loopStart, tc.loopLength)
+
+		// Read across the end of the loop region several times.
+		got, err := readSamples(r, 30)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		for i, s := range got {
+			if w := tc.want(i); s != w {
+				t.Errorf("%s: sample %d: got %d, want %d", tc.name, i, s, w)
+				break
+			}
+		}
+
+		// Seek past the intro and the loop region.
+		for _, pos := range []int{0, 1, 7, 12, 25, 1000} {
+			if _, err := r.Seek(int64(pos)*2, io.SeekStart); err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+				continue
+			}
+			got, err := readSamples(r, 1)
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+				continue
+			}
+			if w := tc.want(pos); got[0] != w {
+				t.Errorf("%s: sample %d after Seek: got %d, want %d", tc.name, pos, got[0], w)
+			}
+			// Seek relative to the position after the sample.
+			if _, err := r.Seek(2*2, io.SeekCurrent); err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+				continue
+			}
+			got, err = readSamples(r, 1)
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+				continue
+			}
+			if w := tc.want(pos + 3); got[0] != w {
+				t.Errorf("%s: sample %d after Seek: got %d, want %d", tc.name, pos+3, got[0], w)
+			}
+		}
+	}
+}
+
+func TestLoopReaderReadStopsAtLoopEnd(t *testing.T) {
+	r := NewLoopReader(bytes.NewReader(testPCM(10)), 44100, 1, 2, 5)
+	b := make([]byte, 100)
+	n, err := r.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 7*2 {
+		t.Errorf("got %d bytes, want %d", n, 7*2)
+	}
+	n, err = r.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5*2 {
+		t.Errorf("got %d bytes, want %d", n, 5*2)
+	}
+	if s := binary.LittleEndian.Uint16(b); s != 2 {
+		t.Errorf("got %d, want 2", s)
+	}
+}
+
+func TestLoopReaderErrors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		sampleRate int
+		channels   int
+		loopStart  int64
+		loopLength int64
+	}{
+		{name: "zero sample rate", sampleRate: 0, channels: 1, loopStart: 0, loopLength: 1},
+		{name: "zero channels", sampleRate: 44100, channels: 0, loopStart: 0, loopLength: 1},
+		{name: "negative LOOPSTART", sampleRate: 44100, channels: 1, loopStart: -1, loopLength: 1},
+		{name: "negative LOOPLENGTH", sampleRate: 44100, channels: 1, loopStart: 0, loopLength: -1},
+		{name: "overflow", sampleRate: 44100, channels: 2, loopStart: 1 << 61, loopLength: 1},
+		{name: "LOOPSTART at the end", sampleRate: 44100, channels: 1, loopStart: 10, loopLength: 0},
+	}
+	for _, tc := range testCases {
+		r := NewLoopReader(bytes.NewReader(testPCM(10)), tc.sampleRate, tc.channels, tc.loopStart, tc.loopLength)
+		if _, err := r.Read(make([]byte, 2)); err == nil {
+			t.Errorf("%s: Read: got nil, want an error", tc.name)
+		}
+		if _, err := r.Seek(0, io.SeekStart); err == nil {
+			t.Errorf("%s: Seek: got nil, want an error", tc.name)
+		}
+	}
+
+	r := NewLoopReader(bytes.NewReader(testPCM(10)), 44100, 1, 2, 5)
+	if _, err := r.Seek(0, io.SeekEnd); err == nil {
+		t.Errorf("io.SeekEnd: got nil, want an error")
+	}
+	if _, err := r.Seek(-1, io.SeekStart); err == nil {
+		t.Errorf("negative position: got nil, want an error")
+	}
+
+	// The decoded stream is shorter than the loop region.
+	r = NewLoopReader(bytes.NewReader(testPCM(10)), 44100, 1, 2, 100)
+	if _, err := io.ReadFull(r, make([]byte, 20*2)); err == nil {
+		t.Errorf("short stream: got nil, want an error")
+	}
+}
+
+func TestInfiniteLoopBytes(t *testing.T) {
+	testCases := []struct {
+		name       string
+		info       Info
+		sampleRate int
+		intro      int64
+		loop       int64
+	}{
+		{
+			name:       "same sample rate",
+			info:       Info{SampleRate: 44100, LoopStart: 1000, LoopLength: 2000, LengthTag: LengthTagLoopLength},
+			sampleRate: 44100,
+			intro:      1000 * 4,
+			loop:       2000 * 4,
+		},
+		{
+			name:       "44.1 kHz to 48 kHz",
+			info:       Info{SampleRate: 44100, LoopStart: 44100, LoopLength: 88200, LengthTag: LengthTagLoopLength},
+			sampleRate: 48000,
+			intro:      48000 * 4,
+			loop:       96000 * 4,
+		},
+		{
+			// The positions 1000 and 3000 are converted to 1088 and 3265, so the loop is 2177 frames,
+			// while converting the length 2000 would make it 2176 frames.
+			name:       "44.1 kHz to 48 kHz with rounding",
+			info:       Info{SampleRate: 44100, LoopStart: 1000, LoopLength: 2000, LengthTag: LengthTagLoopLength},
+			sampleRate: 48000,
+			intro:      1088 * 4,
+			loop:       2177 * 4,
+		},
+		{
+			name:       "to the end",
+			info:       Info{SampleRate: 44100, TotalSamples: 88200, LoopStart: 44100, LengthTag: LengthTagNone},
+			sampleRate: 48000,
+			intro:      48000 * 4,
+			loop:       48000 * 4,
+		},
+	}
+	for _, tc := range testCases {
+		intro, loop, err := tc.info.InfiniteLoopBytes(tc.sampleRate, 4)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if intro != tc.intro || loop != tc.loop {
+			t.Errorf("%s: got (%d, %d), want (%d, %d)", tc.name, intro, loop, tc.intro, tc.loop)
+		}
+	}
+
+	for _, info := range []Info{
+		{SampleRate: 0, LoopStart: 0, LoopLength: 1, LengthTag: LengthTagLoopLength},
+		{SampleRate: 44100, TotalSamples: 100, LoopStart: 100, LengthTag: LengthTagNone},
+		{SampleRate: 44100, LoopStart: 1 << 62, LoopLength: 1, LengthTag: LengthTagLoopLength},
+	} {
+		if _, _, err := info.InfiniteLoopBytes(48000, 4); err == nil {
+			t.Errorf("%+v: got nil, want an error", info)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ErrInvalidSignature is returned when a manifest's signature does not match its entries.
+var ErrInvalidSignature = errors.New("oggloop: invalid manifest signature")
+
+// ManifestEntry is an asset in a Manifest.
+type ManifestEntry struct {
+	// Name is the path of the asset in the file system.
+	Name string `json:"name"`
+
+	// SHA256 is the hex-encoded SHA-256 hash of the asset's content.
+	SHA256 string `json:"sha256"`
+
+	// LoopStart is the LOOPSTART value of the asset.
+	LoopStart int64 `json:"loopStart"`
+
+	// LoopLength is the LOOPLENGTH value of the asset.
+	LoopLength int64 `json:"loopLength"`
+}
+
+// Manifest records the content hashes and the loop meta data of assets, so that the assets can be
+// verified not to have changed since the manifest was generated.
+//
+// Manifest can be marshaled to and unmarshaled from JSON as it is.
+type Manifest struct {
+	// Entries is the list of the assets.
+	Entries []ManifestEntry `json:"entries"`
+
+	// Signature is the Ed25519 signature of Entries. Signature is empty when the manifest is not signed.
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// GenerateManifest generates an unsigned manifest for the given Ogg/Vorbis files in fsys.
+func GenerateManifest(fsys fs.FS, names []string) (*Manifest, error) {
+	m := &Manifest{}
+	for _, name := range names {
+		e, err := manifestEntry(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		m.Entries = append(m.Entries, e)
+	}
+	return m, nil
+}
+
+func manifestEntry(fsys fs.FS, name string) (ManifestEntry, error) {
+	buf, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+	loopStart, loopLength, err := ReadBytes(buf)
+	if err != nil && !errors.Is(err, ErrNoLoopTags) {
+		return ManifestEntry{}, fmt.Errorf("oggloop: %s: %w", name, err)
+	}
+	h := sha256.Sum256(buf)
+	return ManifestEntry{
+		Name:       name,
+		SHA256:     hex.EncodeToString(h[:]),
+		LoopStart:  loopStart,
+		LoopLength: loopLength,
+	}, nil
+}
+
+func (m *Manifest) signedBytes() ([]byte, error) {
+	return json.Marshal(m.Entries)
+}
+
+// Sign signs the manifest's entries with the given key.
+func (m *Manifest) Sign(key ed25519.PrivateKey) error {
+	b, err := m.signedBytes()
+	if err != nil {
+		return err
+	}
+	m.Signature = ed25519.Sign(key, b)
+	return nil
+}
+
+// Verify verifies the manifest's signature with the given key in the same way as VerifySignature, and then
+// verifies the assets in fsys in the same way as VerifyHashes.
+//
+// key must not be nil. Use VerifyHashes for an unsigned manifest.
+func (m *Manifest) Verify(fsys fs.FS, key ed25519.PublicKey) error {
+	if err := m.VerifySignature(key); err != nil {
+		return err
+	}
+	return m.VerifyHashes(fsys)
+}
+
+// VerifySignature verifies the manifest's signature with the given key.
+//
+// VerifySignature returns ErrInvalidSignature when the signature does not match, including when the
+// manifest is not signed. VerifySignature returns an error when key is not a valid public key, e.g. nil.
+func (m *Manifest) VerifySignature(key ed25519.PublicKey) error {
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("oggloop: invalid public key size: %d", len(key))
+	}
+	b, err := m.signedBytes()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, b, m.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyHashes verifies that every asset in fsys still has the recorded content hash and loop meta data.
+// VerifyHashes does not verify the signature, so the manifest itself must come from a trusted source.
+func (m *Manifest) VerifyHashes(fsys fs.FS) error {
+	for _, want := range m.Entries {
+		got, err := manifestEntry(fsys, want.Name)
+		if err != nil {
+			return err
+		}
+		if got.SHA256 != want.SHA256 {
+			return fmt.Errorf("oggloop: %s: content hash mismatch", want.Name)
+		}
+		if got.LoopStart != want.LoopStart || got.LoopLength != want.LoopLength {
+			return fmt.Errorf("oggloop: %s: loop mismatch: got (%d, %d), want (%d, %d)", want.Name, got.LoopStart, got.LoopLength, want.LoopStart, want.LoopLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestManifestVerify(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bgm.ogg": &fstest.MapFile{Data: testVorbis([]string{"LOOPSTART=1000", "LOOPLENGTH=5000"}, 1)},
+	}
+	m, err := GenerateManifest(fsys, []string{"bgm.ogg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.VerifyHashes(fsys); err != nil {
+		t.Errorf("VerifyHashes: %v", err)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An unsigned manifest does not pass the signature check.
+	if err := m.Verify(fsys, pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify before Sign: got %v, want ErrInvalidSignature", err)
+	}
+	if err := m.Sign(priv); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Verify(fsys, pub); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := m.Verify(fsys, nil); err == nil {
+		t.Errorf("Verify with a nil key: got no error")
+	}
+
+	m.Entries[0].LoopStart = 0
+	if err := m.Verify(fsys, pub); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify after tampering: got %v, want ErrInvalidSignature", err)
+	}
+}
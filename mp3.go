@@ -0,0 +1,554 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrNotMP3 is returned when the stream is not an MP3 stream.
+var ErrNotMP3 = errors.New("oggloop: not an MP3 stream")
+
+// maxMP3SyncSearch is the maximum number of bytes to search for the first MPEG audio frame after the
+// ID3v2 tag.
+const maxMP3SyncSearch = 64 * 1024
+
+// ReadMP3 reads the given src as an MP3 stream and returns the audio parameters and the loop meta data
+// values in the user-defined text information (TXXX) frames of the ID3v2 tag, e.g. a frame whose
+// description is LOOPSTART. The frames are matched in the same way as the fields of Vorbis comments.
+//
+// ReadMP3 returns ErrNotMP3 when src is not an MP3 stream, and ErrNoCommentHeader when the stream does not
+// have an ID3v2 tag.
+//
+// The audio parameters come from the first MPEG audio frame. TotalSamples comes from the Xing or Info
+// header, excluding the encoder delay and padding in the LAME header if any. TotalSamples is 0 when the
+// stream does not have a Xing or Info header.
+// ReadMP3 stops reading right after the first MPEG audio frame.
+func ReadMP3(src io.Reader) (Info, error) {
+	return NewDecoder(src, nil).ReadMP3()
+}
+
+// readMP3 also returns the TXXX frames as comment fields in the same way as readID3v2.
+func readMP3(pr *pageReader, tags *tagMatcher) (Info, []byte, error) {
+	head, err := pr.readBytes(4)
+	if err == io.ErrUnexpectedEOF {
+		return Info{}, nil, ErrNotMP3
+	}
+	if err != nil {
+		return Info{}, nil, err
+	}
+	if string(head[:3]) != "ID3" {
+		if _, ok := parseMPEGFrameHeader(head); ok {
+			return Info{}, nil, ErrNoCommentHeader
+		}
+		return Info{}, nil, ErrNotMP3
+	}
+	comment, err := readID3v2(pr, head)
+	if err != nil {
+		return Info{}, nil, err
+	}
+
+	var info Info
+	h, err := findMPEGFrame(pr)
+	if err != nil {
+		return Info{}, nil, err
+	}
+	info.SampleRate = h.sampleRate
+	info.Channels = h.channels
+	info.TotalSamples, err = readXingHeader(pr, h)
+	if err != nil {
+		return Info{}, nil, err
+	}
+
+	fields := id3Fields(comment)
+	loops, err := tags.loopTags(fields, info.SampleRate)
+	if err != nil {
+		return Info{}, nil, err
+	}
+	info.setLoops(loops)
+	info.LoopCount, info.LoopFade = tags.auxTags(fields)
+	return info, comment, nil
+}
+
+// id3Fields splits the comment fields returned by readID3v2.
+func id3Fields(comment []byte) []string {
+	// Each field is followed by a NUL byte.
+	fields := strings.TrimSuffix(string(comment), "\x00")
+	if fields == "" {
+		return nil
+	}
+	return strings.Split(fields, "\x00")
+}
+
+// readID3v2 reads an ID3v2 tag whose first 4 bytes are already read as head, and returns the TXXX frames
+// as comment fields like "LOOPSTART=1000", separated by NUL bytes.
+// https://id3.org/id3v2.4.0-structure
+func readID3v2(pr *pageReader, head []byte) ([]byte, error) {
+	rest, err := pr.readBytes(6)
+	if err != nil {
+		return nil, err
+	}
+	h := append(head[:4:4], rest...)
+	version := h[3]
+	flags := h[5]
+	size := id3v2Size(h[6:10])
+	if err := pr.checkCommentSize(int(size)); err != nil {
+		return nil, err
+	}
+	data, err := pr.readBytes(int(size))
+	if err != nil {
+		return nil, err
+	}
+	if flags&0x10 != 0 {
+		if err := pr.skipBytes(10); err != nil {
+			return nil, err
+		}
+	}
+
+	// Before ID3v2.4, the unsynchronisation applies to the whole of the tag.
+	if version < 4 && flags&0x80 != 0 {
+		data = resynchronize(data)
+	}
+	if flags&0x40 != 0 && version >= 3 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("%w: broken ID3v2 extended header", ErrCorruptPage)
+		}
+		// The size of an ID3v2.3 extended header excludes the size field itself.
+		n := int64(binary.BigEndian.Uint32(data[:4])) + 4
+		if version >= 4 {
+			n = id3v2Size(data[:4])
+		}
+		if n > int64(len(data)) {
+			return nil, fmt.Errorf("%w: broken ID3v2 extended header", ErrCorruptPage)
+		}
+		data = data[n:]
+	}
+
+	idSize, headerSize := 4, 10
+	if version < 3 {
+		idSize, headerSize = 3, 6
+	}
+	var comment []byte
+	for len(data) >= headerSize {
+		id := string(data[:idSize])
+		// The rest is padding.
+		if id[0] == 0 {
+			break
+		}
+		var n int64
+		var frameFlags uint16
+		switch version {
+		case 2:
+			n = int64(data[3])<<16 | int64(data[4])<<8 | int64(data[5])
+		case 3:
+			n = int64(binary.BigEndian.Uint32(data[4:8]))
+			frameFlags = binary.BigEndian.Uint16(data[8:10])
+		default:
+			n = id3v2Size(data[4:8])
+			frameFlags = binary.BigEndian.Uint16(data[8:10])
+		}
+		if n > int64(len(data)-headerSize) {
+			return nil, fmt.Errorf("%w: broken ID3v2 frame %q", ErrCorruptPage, id)
+		}
+		body := data[headerSize : headerSize+int(n)]
+		data = data[headerSize+int(n):]
+
+		if id != "TXXX" && id != "TXX" {
+			continue
+		}
+		body, ok := id3v2FrameBody(version, flags, frameFlags, body)
+		if !ok {
+			continue
+		}
+		desc, value, ok := parseTXXX(body)
+		if !ok {
+			continue
+		}
+		comment = append(comment, desc...)
+		comment = append(comment, '=')
+		comment = append(comment, value...)
+		comment = append(comment, 0)
+	}
+	return comment, nil
+}
+
+// id3v2Size returns the value of a 28-bit synchsafe integer.
+func id3v2Size(b []byte) int64 {
+	return int64(b[0]&0x7f)<<21 | int64(b[1]&0x7f)<<14 | int64(b[2]&0x7f)<<7 | int64(b[3]&0x7f)
+}
+
+// id3v2FrameBody removes the extra data indicated by the frame flags from the body of a frame.
+// id3v2FrameBody returns false when the frame is compressed or encrypted.
+func id3v2FrameBody(version, tagFlags byte, frameFlags uint16, body []byte) ([]byte, bool) {
+	switch version {
+	case 3:
+		// Compression and encryption.
+		if frameFlags&0x00c0 != 0 {
+			return nil, false
+		}
+		// Grouping identity.
+		if frameFlags&0x0020 != 0 {
+			if len(body) < 1 {
+				return nil, false
+			}
+			body = body[1:]
+		}
+	case 4:
+		// Compression and encryption.
+		if frameFlags&0x000c != 0 {
+			return nil, false
+		}
+		// Grouping identity.
+		if frameFlags&0x0040 != 0 {
+			if len(body) < 1 {
+				return nil, false
+			}
+			body = body[1:]
+		}
+		// Data length indicator.
+		if frameFlags&0x0001 != 0 {
+			if len(body) < 4 {
+				return nil, false
+			}
+			body = body[4:]
+		}
+		if frameFlags&0x0002 != 0 || tagFlags&0x80 != 0 {
+			body = resynchronize(body)
+		}
+	}
+	return body, true
+}
+
+// resynchronize reverts the unsynchronisation of ID3v2, which inserts a zero byte after each 0xff byte.
+func resynchronize(data []byte) []byte {
+	if !bytes.Contains(data, []byte{0xff, 0x00}) {
+		return data
+	}
+	b := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		b = append(b, data[i])
+		if data[i] == 0xff && i+1 < len(data) && data[i+1] == 0x00 {
+			i++
+		}
+	}
+	return b
+}
+
+// parseTXXX parses the body of a TXXX frame and returns the description and the value in UTF-8.
+func parseTXXX(body []byte) (desc, value []byte, ok bool) {
+	if len(body) < 1 {
+		return nil, nil, false
+	}
+	encoding, text := body[0], body[1:]
+
+	// UTF-16 strings are terminated by two zero bytes at an even offset.
+	var i int
+	switch encoding {
+	case 0, 3:
+		i = bytes.IndexByte(text, 0)
+		if i < 0 {
+			return nil, nil, false
+		}
+		desc, value = text[:i], text[i+1:]
+	case 1, 2:
+		for i = 0; i+1 < len(text); i += 2 {
+			if text[i] == 0 && text[i+1] == 0 {
+				break
+			}
+		}
+		if i+1 >= len(text) {
+			return nil, nil, false
+		}
+		desc, value = text[:i], text[i+2:]
+	default:
+		return nil, nil, false
+	}
+	return decodeID3v2Text(encoding, desc), decodeID3v2Text(encoding, value), true
+}
+
+// decodeID3v2Text converts a string in the given ID3v2 text encoding to UTF-8.
+func decodeID3v2Text(encoding byte, text []byte) []byte {
+	switch encoding {
+	case 0:
+		// ISO-8859-1 maps bytes to the same code points.
+		var b []byte
+		for _, c := range text {
+			if c == 0 {
+				break
+			}
+			b = utf8.AppendRune(b, rune(c))
+		}
+		return b
+	case 1, 2:
+		bigEndian := encoding == 2
+		if len(text) >= 2 {
+			switch {
+			case text[0] == 0xfe && text[1] == 0xff:
+				bigEndian = true
+				text = text[2:]
+			case text[0] == 0xff && text[1] == 0xfe:
+				bigEndian = false
+				text = text[2:]
+			}
+		}
+		u := make([]uint16, 0, len(text)/2)
+		for i := 0; i+1 < len(text); i += 2 {
+			var c uint16
+			if bigEndian {
+				c = binary.BigEndian.Uint16(text[i:])
+			} else {
+				c = binary.LittleEndian.Uint16(text[i:])
+			}
+			if c == 0 {
+				break
+			}
+			u = append(u, c)
+		}
+		return []byte(string(utf16.Decode(u)))
+	default:
+		if i := bytes.IndexByte(text, 0); i >= 0 {
+			text = text[:i]
+		}
+		return text
+	}
+}
+
+// mpegFrameHeader is the header of an MPEG audio frame.
+// http://www.mp3-tech.org/programmer/frame_header.html
+type mpegFrameHeader struct {
+	// version is 1 for MPEG-1, 2 for MPEG-2, and 25 for MPEG-2.5.
+	version int
+
+	layer      int
+	sampleRate int
+	channels   int
+
+	// size is the size of the frame in bytes including the header, or 0 for the free format.
+	size int
+}
+
+var (
+	mpegSampleRates = [3]int{44100, 48000, 32000}
+
+	// mpegBitrates is the bitrates in kbps, indexed by MPEG-1 or not, the layer minus 1, and the bitrate
+	// index.
+	mpegBitrates = [2][3][15]int{
+		{
+			{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+			{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		},
+		{
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		},
+	}
+)
+
+func parseMPEGFrameHeader(b []byte) (mpegFrameHeader, bool) {
+	if b[0] != 0xff || b[1]&0xe0 != 0xe0 {
+		return mpegFrameHeader{}, false
+	}
+	var h mpegFrameHeader
+	switch b[1] >> 3 & 0x03 {
+	case 0:
+		h.version = 25
+	case 2:
+		h.version = 2
+	case 3:
+		h.version = 1
+	default:
+		return mpegFrameHeader{}, false
+	}
+	h.layer = 4 - int(b[1]>>1&0x03)
+	if h.layer == 4 {
+		return mpegFrameHeader{}, false
+	}
+	bitrateIndex := int(b[2] >> 4)
+	sampleRateIndex := int(b[2] >> 2 & 0x03)
+	if bitrateIndex == 15 || sampleRateIndex == 3 {
+		return mpegFrameHeader{}, false
+	}
+	h.sampleRate = mpegSampleRates[sampleRateIndex]
+	switch h.version {
+	case 2:
+		h.sampleRate /= 2
+	case 25:
+		h.sampleRate /= 4
+	}
+	h.channels = 2
+	if b[3]>>6 == 3 {
+		h.channels = 1
+	}
+
+	var v int
+	if h.version != 1 {
+		v = 1
+	}
+	bitrate := mpegBitrates[v][h.layer-1][bitrateIndex] * 1000
+	padding := int(b[2] >> 1 & 0x01)
+	switch {
+	case bitrate == 0:
+	case h.layer == 1:
+		h.size = (12*bitrate/h.sampleRate + padding) * 4
+	case h.layer == 3 && h.version != 1:
+		h.size = 72*bitrate/h.sampleRate + padding
+	default:
+		h.size = 144*bitrate/h.sampleRate + padding
+	}
+	return h, true
+}
+
+// samplesPerFrame returns the number of PCM samples per channel in a frame.
+func (h *mpegFrameHeader) samplesPerFrame() int64 {
+	switch {
+	case h.layer == 1:
+		return 384
+	case h.layer == 3 && h.version != 1:
+		return 576
+	default:
+		return 1152
+	}
+}
+
+// findMPEGFrame finds the first MPEG audio frame and reads its header.
+func findMPEGFrame(pr *pageReader) (mpegFrameHeader, error) {
+	// Search chunks of bytes instead of reading byte by byte. b is the bytes not searched yet.
+	const chunkSize = 4096
+	var (
+		b    []byte
+		read int
+	)
+	for read < maxMP3SyncSearch+4 {
+		n := maxMP3SyncSearch + 4 - read
+		if n > chunkSize {
+			n = chunkSize
+		}
+		chunk, err := pr.readBytesUpTo(n)
+		if err != nil {
+			return mpegFrameHeader{}, err
+		}
+		if len(chunk) == 0 {
+			break
+		}
+		read += len(chunk)
+		b = append(b, chunk...)
+
+		i := 0
+		for {
+			j := bytes.IndexByte(b[i:], 0xff)
+			if j < 0 {
+				i = len(b)
+				break
+			}
+			i += j
+			// The header might continue in the next chunk.
+			if len(b)-i < 4 {
+				break
+			}
+			if h, ok := parseMPEGFrameHeader(b[i : i+4]); ok {
+				// The rest of the frame is read by readXingHeader.
+				pr.unreadBytes(b[i+4:])
+				return h, nil
+			}
+			i++
+		}
+		b = append(b[:0], b[i:]...)
+	}
+	return mpegFrameHeader{}, ErrNotMP3
+}
+
+// readXingHeader reads the rest of the first MPEG audio frame, and returns the number of samples in the
+// Xing or Info header, or 0 when the frame does not have the header.
+// http://gabriel.mp3-tech.org/mp3infotag.html
+func readXingHeader(pr *pageReader, h mpegFrameHeader) (int64, error) {
+	if h.layer != 3 || h.size == 0 {
+		return 0, nil
+	}
+	data, err := pr.readBytes(h.size - 4)
+	// The stream might have only a broken frame. This is not a problem to read the tags.
+	if err == io.ErrUnexpectedEOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// The Xing header follows the side information.
+	offset := 17
+	switch {
+	case h.version == 1 && h.channels == 2:
+		offset = 32
+	case h.version != 1 && h.channels == 1:
+		offset = 9
+	}
+	if len(data) < offset+8 {
+		return 0, nil
+	}
+	x := data[offset:]
+	if sig := string(x[:4]); sig != "Xing" && sig != "Info" {
+		return 0, nil
+	}
+	flags := binary.BigEndian.Uint32(x[4:8])
+	x = x[8:]
+
+	var frames int64 = -1
+	if flags&0x01 != 0 {
+		if len(x) < 4 {
+			return 0, nil
+		}
+		frames = int64(binary.BigEndian.Uint32(x[:4]))
+		x = x[4:]
+	}
+	if frames < 0 {
+		return 0, nil
+	}
+	// Skip the number of bytes, the table of contents and the quality indicator.
+	for _, f := range []struct {
+		flag uint32
+		size int
+	}{{0x02, 4}, {0x04, 100}, {0x08, 4}} {
+		if flags&f.flag == 0 {
+			continue
+		}
+		if len(x) < f.size {
+			x = nil
+			break
+		}
+		x = x[f.size:]
+	}
+
+	samples := frames * h.samplesPerFrame()
+	// The LAME header has the encoder delay and padding as 12-bit integers.
+	if len(x) >= 24 {
+		if sig := string(x[:4]); sig == "LAME" || sig == "Lavc" || sig == "Lavf" {
+			delay := int64(x[21])<<4 | int64(x[22]>>4)
+			padding := int64(x[22]&0x0f)<<8 | int64(x[23])
+			samples -= delay + padding
+		}
+	}
+	if samples < 0 {
+		samples = 0
+	}
+	return samples, nil
+}
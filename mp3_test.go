@@ -0,0 +1,267 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+)
+
+// testID3v2 returns an ID3v2 tag of the given version, flags and body after the header.
+func testID3v2(version, flags byte, body []byte) []byte {
+	n := len(body)
+	b := []byte{'I', 'D', '3', version, 0, flags, byte(n>>21) & 0x7f, byte(n>>14) & 0x7f, byte(n>>7) & 0x7f, byte(n) & 0x7f}
+	return append(b, body...)
+}
+
+// testID3v2Frame returns an ID3v2 frame of the given version.
+func testID3v2Frame(version byte, id string, flags uint16, body []byte) []byte {
+	n := len(body)
+	var b []byte
+	switch version {
+	case 2:
+		b = append([]byte(id), byte(n>>16), byte(n>>8), byte(n))
+	case 3:
+		b = append([]byte(id), byte(n>>24), byte(n>>16), byte(n>>8), byte(n), byte(flags>>8), byte(flags))
+	default:
+		b = append([]byte(id), byte(n>>21)&0x7f, byte(n>>14)&0x7f, byte(n>>7)&0x7f, byte(n)&0x7f, byte(flags>>8), byte(flags))
+	}
+	return append(b, body...)
+}
+
+// testTXXX returns the body of a TXXX frame in ISO-8859-1.
+func testTXXX(desc, value string) []byte {
+	b := append([]byte{0}, desc...)
+	b = append(b, 0)
+	return append(b, value...)
+}
+
+// testUTF16 encodes s in UTF-16 in the given byte order, optionally with a BOM, and a terminator.
+func testUTF16(s string, bigEndian, bom bool) []byte {
+	var b []byte
+	put := func(c uint16) {
+		if bigEndian {
+			b = append(b, byte(c>>8), byte(c))
+		} else {
+			b = append(b, byte(c), byte(c>>8))
+		}
+	}
+	if bom {
+		put(0xfeff)
+	}
+	for _, c := range utf16.Encode([]rune(s)) {
+		put(c)
+	}
+	put(0)
+	return b
+}
+
+// unsynchronize inserts a zero byte after each 0xff byte in the same way as the ID3v2 unsynchronisation.
+func unsynchronize(data []byte) []byte {
+	var b []byte
+	for _, c := range data {
+		b = append(b, c)
+		if c == 0xff {
+			b = append(b, 0)
+		}
+	}
+	return b
+}
+
+// testMPEGFrame returns an MPEG-1 Layer III frame of 44.1 kHz stereo at 128 kbps. If frames is not negative,
+// the frame has a Xing or Info header with the number of frames, and a LAME header with the encoder delay
+// and padding.
+func testMPEGFrame(sig string, frames int, delay, padding int) []byte {
+	const size = 144 * 128000 / 44100
+	b := make([]byte, size)
+	copy(b, []byte{0xff, 0xfb, 0x90, 0x00})
+	if frames < 0 {
+		return b
+	}
+	// The Xing header follows the 32-byte side information.
+	x := b[4+32:]
+	copy(x, sig)
+	// The number of frames, the number of bytes and the table of contents.
+	binary.BigEndian.PutUint32(x[4:], 0x07)
+	binary.BigEndian.PutUint32(x[8:], uint32(frames))
+	lame := x[8+4+4+100:]
+	copy(lame, "LAME")
+	lame[21] = byte(delay >> 4)
+	lame[22] = byte(delay<<4) | byte(padding>>8)
+	lame[23] = byte(padding)
+	return b
+}
+
+func TestReadMP3(t *testing.T) {
+	loopFrames := func(version byte) []byte {
+		id := "TXXX"
+		if version == 2 {
+			id = "TXX"
+		}
+		b := testID3v2Frame(version, id, 0, testTXXX("LOOPSTART", "1000"))
+		return append(b, testID3v2Frame(version, id, 0, testTXXX("LOOPLENGTH", "5000"))...)
+	}
+	// priv is a frame with 0xff bytes, which are unsynchronised.
+	priv := func(version byte, flags uint16) []byte {
+		body := []byte("x\x00\xff\xe0\xff\x00")
+		if flags&0x02 != 0 {
+			body = unsynchronize(body)
+		}
+		return testID3v2Frame(version, "PRIV", flags, body)
+	}
+	frame := testMPEGFrame("Info", 100, 576, 1000)
+
+	testCases := []struct {
+		name    string
+		data    []byte
+		start   int64
+		length  int64
+		samples int64
+	}{
+		{
+			name:    "ID3v2.2",
+			data:    append(testID3v2(2, 0, loopFrames(2)), frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name:    "ID3v2.3",
+			data:    append(testID3v2(3, 0, loopFrames(3)), frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name:    "ID3v2.4",
+			data:    append(testID3v2(4, 0, loopFrames(4)), frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name:    "ID3v2.3 unsynchronisation",
+			data:    append(testID3v2(3, 0x80, unsynchronize(append(priv(3, 0), loopFrames(3)...))), frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name:    "ID3v2.4 frame unsynchronisation",
+			data:    append(testID3v2(4, 0, append(priv(4, 0x02), loopFrames(4)...)), frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name: "ID3v2.3 extended header",
+			// The size excludes the size field itself, followed by the flags and the size of the padding.
+			data:    append(testID3v2(3, 0x40, append([]byte{0, 0, 0, 6, 0, 0, 0, 0, 0, 0}, loopFrames(3)...)), frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name: "ID3v2.4 extended header",
+			// The synchsafe size includes the size field itself, followed by the number of flag bytes and
+			// the flags.
+			data:    append(testID3v2(4, 0x40, append([]byte{0, 0, 0, 6, 1, 0}, loopFrames(4)...)), frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name: "UTF-16 with a big-endian BOM",
+			data: append(testID3v2(3, 0, append(
+				testID3v2Frame(3, "TXXX", 0, append(append([]byte{1}, testUTF16("LOOPSTART", true, true)...), testUTF16("1000", true, true)...)),
+				testID3v2Frame(3, "TXXX", 0, append(append([]byte{1}, testUTF16("LOOPLENGTH", true, true)...), testUTF16("5000", true, true)...))...)),
+				frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name: "UTF-16 with a little-endian BOM",
+			data: append(testID3v2(3, 0, append(
+				testID3v2Frame(3, "TXXX", 0, append(append([]byte{1}, testUTF16("LOOPSTART", false, true)...), testUTF16("1000", false, true)...)),
+				testID3v2Frame(3, "TXXX", 0, append(append([]byte{1}, testUTF16("LOOPLENGTH", false, true)...), testUTF16("5000", false, true)...))...)),
+				frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name: "UTF-16BE without a BOM",
+			data: append(testID3v2(4, 0, append(
+				testID3v2Frame(4, "TXXX", 0, append(append([]byte{2}, testUTF16("LOOPSTART", true, false)...), testUTF16("1000", true, false)...)),
+				testID3v2Frame(4, "TXXX", 0, append(append([]byte{2}, testUTF16("LOOPLENGTH", true, false)...), testUTF16("5000", true, false)...))...)),
+				frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+		{
+			name:    "Xing header",
+			data:    append(testID3v2(3, 0, loopFrames(3)), testMPEGFrame("Xing", 10, 0, 0)...),
+			start:   1000,
+			length:  5000,
+			samples: 10 * 1152,
+		},
+		{
+			name:   "no Xing header",
+			data:   append(testID3v2(3, 0, loopFrames(3)), testMPEGFrame("", -1, 0, 0)...),
+			start:  1000,
+			length: 5000,
+		},
+		{
+			name:    "junk before the frame",
+			data:    append(append(testID3v2(3, 0, loopFrames(3)), bytes.Repeat([]byte{0xff, 0}, 5000)...), frame...),
+			start:   1000,
+			length:  5000,
+			samples: 100*1152 - 576 - 1000,
+		},
+	}
+	for _, tc := range testCases {
+		info, err := ReadMP3(bytes.NewReader(tc.data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if info.SampleRate != 44100 || info.Channels != 2 {
+			t.Errorf("%s: got %d Hz and %d channels, want 44100 Hz and 2 channels", tc.name, info.SampleRate, info.Channels)
+		}
+		if info.LoopStart != tc.start || info.LoopLength != tc.length {
+			t.Errorf("%s: got (%d, %d), want (%d, %d)", tc.name, info.LoopStart, info.LoopLength, tc.start, tc.length)
+		}
+		if info.TotalSamples != tc.samples {
+			t.Errorf("%s: got TotalSamples %d, want %d", tc.name, info.TotalSamples, tc.samples)
+		}
+	}
+}
+
+func TestReadMP3NoFrame(t *testing.T) {
+	id3 := testID3v2(3, 0, testID3v2Frame(3, "TXXX", 0, testTXXX("LOOPSTART", "1")))
+	for _, data := range [][]byte{
+		id3,
+		append(id3, make([]byte, maxMP3SyncSearch+1000)...),
+		append(append(id3, make([]byte, maxMP3SyncSearch+1)...), testMPEGFrame("", -1, 0, 0)...),
+	} {
+		if _, err := ReadMP3(bytes.NewReader(data)); err != ErrNotMP3 {
+			t.Errorf("got %v, want %v", err, ErrNotMP3)
+		}
+	}
+}
@@ -13,127 +13,461 @@
 // limitations under the License.
 
 // Package oggloop provides a function to get LOOPSTART and LOOPLENGTH information
-// from a Ogg/Vorbis meta data as RPG Maker does.
+// from a Ogg/Vorbis, Ogg/Opus or Ogg FLAC meta data as RPG Maker does.
 package oggloop
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
-	"regexp"
-	"strconv"
+	"strings"
+	"time"
 )
 
 var (
-	loopStartRe  = regexp.MustCompile(`LOOPSTART=([0-9]+)`)
-	loopLengthRe = regexp.MustCompile(`LOOPLENGTH=([0-9]+)`)
+	// ErrNotOgg is returned when the stream does not start with an Ogg page.
+	ErrNotOgg = errors.New("oggloop: not an Ogg stream")
+
+	// ErrNoVorbisStream is returned when the stream is Ogg but contains none of a Vorbis stream, an Opus
+	// stream and a FLAC stream.
+	ErrNoVorbisStream = errors.New("oggloop: no Vorbis stream")
+
+	// ErrNoCommentHeader is returned when the Vorbis, Opus or FLAC stream does not have a comment header,
+	// when the FLAC stream does not have a VORBIS_COMMENT metadata block, or when the MP3 stream does not
+	// have an ID3v2 tag.
+	ErrNoCommentHeader = errors.New("oggloop: no Vorbis comment header")
+
+	// ErrNoLoopTags is returned when the comment header has none of LOOPSTART, LOOPLENGTH and LOOPEND.
+	ErrNoLoopTags = errors.New("oggloop: no loop tags")
+
+	// ErrCorruptPage is returned when the stream is broken, e.g. a page or a header packet is malformed.
+	// An error wrapping ErrCorruptPage describes the detail.
+	ErrCorruptPage = errors.New("oggloop: corrupt page")
+
+	// ErrLimitExceeded is returned when the stream exceeds a limit specified by DecoderOptions.
+	// An error wrapping ErrLimitExceeded describes the detail.
+	ErrLimitExceeded = errors.New("oggloop: limit exceeded")
 )
 
-type errReader struct {
-	r   io.Reader
-	err error
+var errBrokenIdentHeader = fmt.Errorf("%w: broken identification header", ErrCorruptPage)
+
+type codec int
+
+const (
+	codecVorbis codec = iota + 1
+	codecOpus
+	codecFLAC
+)
+
+// String returns the name of the codec.
+func (c codec) String() string {
+	switch c {
+	case codecVorbis:
+		return "Vorbis"
+	case codecOpus:
+		return "Opus"
+	case codecFLAC:
+		return "FLAC"
+	}
+	return ""
+}
+
+// commentData returns the comment data after the signature of the comment header packet of the codec.
+// commentData returns false when the packet is not a comment header packet.
+func commentData(c codec, packet []byte) ([]byte, bool) {
+	var sig string
+	switch c {
+	case codecVorbis:
+		sig = "\x03vorbis"
+	case codecOpus:
+		sig = "OpusTags"
+	case codecFLAC:
+		// The comment header packet of Ogg FLAC is a VORBIS_COMMENT metadata block with the header.
+		// https://xiph.org/flac/ogg_mapping.html
+		if len(packet) < 4 || packet[0]&0x7f != flacBlockTypeVorbisComment {
+			return nil, false
+		}
+		return packet[4:], true
+	}
+	if !bytes.HasPrefix(packet, []byte(sig)) {
+		return nil, false
+	}
+	return packet[len(sig):], true
+}
+
+// commentFields returns the comment fields of the comment header packet of the codec.
+func commentFields(c codec, packet []byte) ([]string, error) {
+	data, _ := commentData(c, packet)
+	vc, err := parseComment(data)
+	if err != nil {
+		return nil, err
+	}
+	return vc.Fields, nil
+}
+
+// detectCodec returns the codec of the logical stream whose first packet is the given packet.
+// detectCodec returns 0 when the codec is not supported.
+func detectCodec(packet []byte) codec {
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		return codecVorbis
+	case bytes.HasPrefix(packet, []byte("OpusHead")):
+		return codecOpus
+	case bytes.HasPrefix(packet, []byte("\x7fFLAC")):
+		return codecFLAC
+	}
+	return 0
+}
+
+// Read reads the given src as an Ogg/Vorbis, Ogg/Opus or Ogg FLAC stream and returns LOOPSTART and
+// LOOPLENGTH meta data values. Read returns an error when IO error happens.
+//
+// When the comment header has LOOPEND instead of LOOPLENGTH, loopLength is LOOPEND minus LOOPSTART.
+// Use ReadInfo to know which tag loopLength comes from.
+//
+// A loop tag is a comment field whose whole name is a key, e.g. LOOPSTART, and whose whole value is a
+// non-negative integer. The vendor string and the values of the other fields are not searched.
+//
+// Read returns ErrNotOgg, ErrNoVorbisStream or ErrNoCommentHeader when the stream does not have the
+// headers to look for loop tags, and ErrNoLoopTags when the comment header has none of the tags.
+// Read returns an error wrapping ErrCorruptPage when the stream is broken.
+// Read also returns an error when a tag value does not fit in 64-bit integers, or when the end of the
+// loop does not.
+//
+// When the stream multiplexes several logical streams, Read uses the first Vorbis, Opus or FLAC stream in
+// the order of the beginning-of-stream pages, and ignores the tags of the other logical streams. Use
+// DecoderOptions.Serial to choose another logical stream.
+//
+// Read stops reading right after the comment header. If src is an io.Seeker, Read seeks past the page
+// data it does not need instead of reading it.
+func Read(src io.Reader) (loopStart, loopLength int64, err error) {
+	return NewDecoder(src, nil).Read()
 }
 
-func (r *errReader) ReadByte() byte {
-	return r.ReadBytes(1)[0]
+// ReadContext is like Read but stops reading and returns the context's error when ctx is done.
+//
+// ctx is checked between reads of src. A blocking read of src is not interrupted unless src itself
+// supports cancellation, e.g. by a deadline.
+func ReadContext(ctx context.Context, src io.Reader) (loopStart, loopLength int64, err error) {
+	return NewDecoder(src, nil).ReadContext(ctx)
 }
 
-func (r *errReader) ReadBytes(n int) []byte {
-	buf := make([]byte, n)
-	if r.err != nil {
-		return buf
+func readLoop(pr *pageReader, tags *tagMatcher) (loopStart, loopLength int64, err error) {
+	h, err := readHeaders(pr)
+	if err != nil {
+		return 0, 0, err
 	}
-	if n == 0 {
-		return buf
+	// Read does not fail on a broken identification header. The sample rate is needed only for the loop
+	// values in seconds.
+	sampleRate, _, _ := parseIdent(h.codec, h.ident)
+	fields, err := commentFields(h.codec, h.comment)
+	if err != nil {
+		return 0, 0, err
 	}
-	if n < 0 {
-		r.err = fmt.Errorf("oggloop: reading bytes should be positive: %d", n)
-		return buf
+	loops, err := tags.loopTags(fields, sampleRate)
+	if err != nil {
+		return 0, 0, err
 	}
-	if _, err := io.ReadFull(r.r, buf); err != nil {
-		r.err = err
+	if len(loops) == 0 {
+		return 0, 0, ErrNoLoopTags
 	}
-	return buf
+	return loops[0].Start, loops[0].Length, nil
 }
 
-func (r *errReader) Skip(n int) {
-	if r.err != nil {
+// Info represents the audio parameters and the loop meta data of a stream.
+type Info struct {
+	// SampleRate is the sample rate in Hz.
+	//
+	// For Opus, SampleRate is always 48000, as granule positions and loop tags are based on 48 kHz
+	// regardless of the original sample rate.
+	SampleRate int
+
+	// Channels is the number of channels.
+	Channels int
+
+	// TotalSamples is the number of PCM samples per channel, which comes from the granule position of
+	// the last page.
+	TotalSamples int64
+
+	// LoopStart is the LOOPSTART value of the primary loop, which is the first of Loops.
+	LoopStart int64
+
+	// LoopLength is the LOOPLENGTH value, or the length computed from the LOOPEND value.
+	LoopLength int64
+
+	// LengthTag is the tag from which LoopLength comes.
+	LengthTag LengthTag
+
+	// HasLoopStart reports whether the primary loop has LOOPSTART. LoopStart is 0 when it does not.
+	HasLoopStart bool
+
+	// HasLoopLength reports whether the primary loop has LOOPLENGTH or LOOPEND. HasLoopLength is the same
+	// as LengthTag != LengthTagNone.
+	HasLoopLength bool
+
+	// Loops is all the loop regions, including the primary one of LoopStart and LoopLength, e.g. the
+	// ones of LOOPSTART0 and LOOPLENGTH0, or repeated LOOPSTART and LOOPLENGTH.
+	// The loops without indices come first, and the loops with indices follow in the order of the
+	// indices.
+	Loops []Loop
+
+	// LoopCount is the LOOPCOUNT value, the number of times to play the loop region.
+	// LoopCount is 0 when the tag does not exist or is malformed, which usually means to loop forever.
+	LoopCount int
+
+	// LoopFade is the LOOPFADE value, the length of the fade-out after the loops.
+	// LoopFade is 0 when the tag does not exist or is malformed.
+	LoopFade time.Duration
+}
+
+// setLoops sets the loop regions. The first region is the primary one.
+func (i *Info) setLoops(loops []Loop) {
+	i.Loops = loops
+	if len(loops) == 0 {
 		return
 	}
-	if n == 0 {
-		return
+	i.LoopStart = loops[0].Start
+	i.LoopLength = loops[0].Length
+	i.LengthTag = loops[0].LengthTag
+	i.HasLoopStart = loops[0].HasStart
+	i.HasLoopLength = loops[0].LengthTag != LengthTagNone
+}
+
+// ReadInfo reads the given src as an Ogg/Vorbis, Ogg/Opus or Ogg FLAC stream and returns its audio
+// parameters and loop meta data.
+//
+// ReadInfo does not return ErrNoLoopTags. The loop values are 0 when the tags do not exist. Use
+// HasLoopStart and HasLoopLength to distinguish them from the tags with the value 0.
+//
+// Unlike Read, ReadInfo has to find the last page of the logical stream. ReadInfo reads the pages until
+// the end of the logical stream, and if src is an io.Seeker, ReadInfo seeks past the page bodies instead of
+// reading them.
+func ReadInfo(src io.Reader) (Info, error) {
+	return NewDecoder(src, nil).ReadInfo()
+}
+
+func readInfo(pr *pageReader, tags *tagMatcher) (Info, error) {
+	h, err := readHeaders(pr)
+	if err != nil {
+		return Info{}, err
 	}
-	if n < 0 {
-		r.err = fmt.Errorf("oggloop: skipping bytes should be positive: %d", n)
-		return
+
+	granule := h.granule
+	// Read the pages forward until the end of the stream, as a later chain can reuse the serial number.
+	if !h.eos {
+		for {
+			p, err := pr.next(func(p *page) bool {
+				return true
+			})
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return Info{}, err
+			}
+			if p.serial != h.serial {
+				continue
+			}
+			if p.granule != -1 {
+				granule = p.granule
+			}
+			if p.headerType&headerTypeEOS != 0 {
+				break
+			}
+		}
 	}
-	r.ReadBytes(n)
+	return newInfo(h.codec, h.ident, h.comment, granule, tags)
 }
 
-func mustAtoi(str string) int64 {
-	n, err := strconv.ParseInt(str, 10, 64)
+// newInfo creates an Info from the given header packets and the granule position of the last page.
+func newInfo(c codec, ident, comment []byte, granule int64, tags *tagMatcher) (Info, error) {
+	sampleRate, channels, err := parseIdent(c, ident)
 	if err != nil {
-		panic(err)
+		return Info{}, err
+	}
+	info := Info{
+		SampleRate: sampleRate,
+		Channels:   channels,
+	}
+
+	fields, err := commentFields(c, comment)
+	if err != nil {
+		return Info{}, err
+	}
+	loops, err := tags.loopTags(fields, info.SampleRate)
+	if err != nil {
+		return Info{}, err
+	}
+	info.setLoops(loops)
+	info.LoopCount, info.LoopFade = tags.auxTags(fields)
+
+	if granule > 0 {
+		info.TotalSamples = granule
+	}
+	if c == codecOpus {
+		// The first samples as many as the pre-skip are discarded.
+		preSkip := int64(binary.LittleEndian.Uint16(ident[10:12]))
+		info.TotalSamples -= preSkip
+		if info.TotalSamples < 0 {
+			info.TotalSamples = 0
+		}
 	}
-	return n
+	return info, nil
 }
 
-// Read reads the given src as an Ogg/Vorbis stream and returns LOOPSTART and LOOPLENGTH meta data
-// values. Read returns an error when IO error happens.
-func Read(src io.Reader) (loopStart, loopLength int64, err error) {
-	r := &errReader{r: src}
-	defer func() {
-		if r.err != nil {
-			err = r.err
+// parseIdent returns the sample rate and the number of channels in the given identification header packet.
+func parseIdent(c codec, ident []byte) (sampleRate, channels int, err error) {
+	switch c {
+	case codecVorbis:
+		// https://www.xiph.org/vorbis/doc/Vorbis_I_spec.html#x1-630004.2.2
+		if len(ident) < 30 {
+			return 0, 0, errBrokenIdentHeader
+		}
+		return int(binary.LittleEndian.Uint32(ident[12:16])), int(ident[11]), nil
+	case codecOpus:
+		// https://www.rfc-editor.org/rfc/rfc7845#section-5.1
+		if len(ident) < 19 {
+			return 0, 0, errBrokenIdentHeader
 		}
-	}()
+		return 48000, int(ident[9]), nil
+	case codecFLAC:
+		// The identification header packet has a 13-byte header followed by a STREAMINFO metadata block.
+		// https://xiph.org/flac/ogg_mapping.html
+		if len(ident) < 13+4+34 {
+			return 0, 0, errBrokenIdentHeader
+		}
+		sampleRate, channels, _ := parseFLACStreamInfo(ident[13+4:])
+		return sampleRate, channels, nil
+	}
+	return 0, 0, nil
+}
+
+// ReadComments reads the given src as an Ogg/Vorbis, Ogg/Opus or Ogg FLAC stream and returns all the
+// comments in its comment header.
+//
+// As field names are case-insensitive, the keys of the returned map are upper-cased field names. The
+// values for a key are in the order of the comment header. Comments without '=' are ignored.
+func ReadComments(src io.Reader) (map[string][]string, error) {
+	return NewDecoder(src, nil).ReadComments()
+}
+
+func readComments(pr *pageReader) (map[string][]string, error) {
+	h, err := readHeaders(pr)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := commentFields(h.codec, h.comment)
+	if err != nil {
+		return nil, err
+	}
+	return commentMap(fields), nil
+}
 
+// commentMap maps the upper-cased keys of the given comment fields to their values in the same way as
+// ReadComments.
+func commentMap(fields []string) map[string][]string {
+	comments := map[string][]string{}
+	for _, f := range fields {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok {
+			continue
+		}
+		k = strings.ToUpper(k)
+		comments[k] = append(comments[k], v)
+	}
+	return comments
+}
+
+// streamHeader is the header packets of a logical stream.
+type streamHeader struct {
+	codec  codec
+	serial uint32
+
+	// ident is the identification header packet.
+	ident []byte
+
+	// comment is the comment header packet.
+	comment []byte
+
+	// granule is the last granule position of the pages read so far, or -1.
+	granule int64
+
+	// eos reports whether the last page read so far ends the logical stream.
+	eos bool
+}
+
+// readHeaders reads pr and returns the header packets of the logical stream that pr chooses.
+func readHeaders(pr *pageReader) (*streamHeader, error) {
+	h := &streamHeader{
+		granule: -1,
+	}
+	var streamFound bool
+	r := newPacketReader(pr)
 	for {
-		if string(r.ReadBytes(4)) != "OggS" {
+		p, packets, err := r.next(func(p *page) bool {
+			// Only the pages of the chosen stream are needed after the beginning-of-stream pages.
+			return streamFound && p.serial != h.serial
+		})
+		// A well-formed stream can end right after its last page.
+		if err == io.EOF {
 			break
 		}
-		r.Skip(26 - 4)
-		headerFound := false
-		nseg := r.ReadByte()
-		segs := r.ReadBytes(int(nseg))
-
-		for i := 0; i < len(segs); i++ {
-			headerType := r.ReadByte()
-			if segs[i] < 5 {
-				r.Skip(int(segs[i]) - 1)
-				continue
-			}
-			if string(r.ReadBytes(4)) != "vorb" {
-				r.Skip(int(segs[i]) - 5)
-				continue
-			}
+		if err != nil {
+			return nil, err
+		}
 
-			headerFound = true
-			if headerType != 3 {
-				r.Skip(int(segs[i]) - 5)
-				continue
+		// All the beginning-of-stream pages come before any other pages.
+		// Pick the first Vorbis, Opus or FLAC stream among them, or the one of the specified serial number.
+		if !streamFound {
+			if p.headerType&headerTypeBOS == 0 {
+				break
 			}
-
-			// If the segment size is 255, the segment content continues to its next.
-			// https://www.xiph.org/ogg/doc/framing.html
-			size := 0
-			for ; i < len(segs); i++ {
-				size += int(segs[i])
-				if segs[i] < 255 {
-					break
-				}
+			if pr.chooses(p) {
+				h.codec = detectCodec(p.body)
+				h.serial = p.serial
+				h.ident = p.body
+				streamFound = true
 			}
-			size -= 5
-			meta := r.ReadBytes(size)
-			if m := loopStartRe.FindSubmatch(meta); len(m) > 1 {
-				loopStart = mustAtoi(string(m[1]))
+			continue
+		}
+		if p.serial != h.serial {
+			continue
+		}
+		if p.granule != -1 {
+			h.granule = p.granule
+		}
+		h.eos = p.headerType&headerTypeEOS != 0
+
+		// The comment header is the second packet of the logical stream, following the identification
+		// header on the first page.
+		if len(packets) > 0 {
+			if err := pr.checkCommentSize(len(packets[0])); err != nil {
+				return nil, err
 			}
-			if m := loopLengthRe.FindSubmatch(meta); len(m) > 1 {
-				loopLength = mustAtoi(string(m[1]))
+			if _, ok := commentData(h.codec, packets[0]); ok {
+				h.comment = packets[0]
 			}
+			break
 		}
-		if !headerFound {
+		if err := pr.checkCommentSize(r.partialSize(p.serial)); err != nil {
+			return nil, err
+		}
+		if h.eos {
 			break
 		}
 	}
-	return
+
+	if pr.pages == 0 {
+		return nil, ErrNotOgg
+	}
+	if !streamFound {
+		return nil, ErrNoVorbisStream
+	}
+	if h.comment == nil {
+		return nil, ErrNoCommentHeader
+	}
+	return h, nil
 }
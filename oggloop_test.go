@@ -0,0 +1,722 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testPage encodes a page with the given packets. All the packets must finish on the page.
+func testPage(headerType byte, granule int64, serial, seq uint32, packets ...[]byte) []byte {
+	p := page{
+		headerType: headerType,
+		granule:    granule,
+		serial:     serial,
+		seq:        seq,
+	}
+	for _, packet := range packets {
+		n := len(packet)
+		for ; n >= 255; n -= 255 {
+			p.segments = append(p.segments, 255)
+		}
+		p.segments = append(p.segments, byte(n))
+		p.body = append(p.body, packet...)
+	}
+	return p.bytes()
+}
+
+// testVorbisHeaders returns the identification header and the comment header packets of a 44.1 kHz
+// stereo Vorbis stream with the given comments.
+func testVorbisHeaders(comments []string) (ident, comment []byte) {
+	ident = []byte("\x01vorbis")
+	ident = append(ident, 0, 0, 0, 0, 2)
+	ident = appendUint32(ident, 44100)
+	ident = append(ident, make([]byte, 12)...)
+	ident = append(ident, 0xb8, 1)
+
+	vc := &vorbisComment{
+		Comments: Comments{
+			Vendor: "test",
+			Fields: comments,
+		},
+		trailer: []byte{1},
+	}
+	comment = append([]byte("\x03vorbis"), vc.bytes()...)
+	return ident, comment
+}
+
+// testVorbis returns an Ogg/Vorbis stream with the given comments, followed by the given number of audio
+// pages of 1000 samples each.
+func testVorbis(comments []string, audioPages int) []byte {
+	const serial = 1234
+
+	ident, comment := testVorbisHeaders(comments)
+
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, serial, 0, ident))
+	buf.Write(testPage(0, 0, serial, 1, comment, []byte("\x05vorbis")))
+	for i := 0; i < audioPages; i++ {
+		var headerType byte
+		if i == audioPages-1 {
+			headerType = headerTypeEOS
+		}
+		buf.Write(testPage(headerType, int64(i+1)*1000, serial, uint32(i+2), make([]byte, 100)))
+	}
+	return buf.Bytes()
+}
+
+// testOpus returns an Ogg/Opus stream with the given comments and a pre-skip of 312 samples, followed by
+// the given number of audio pages of 960 samples each.
+func testOpus(comments []string, audioPages int) []byte {
+	const serial = 5678
+
+	ident := []byte("OpusHead")
+	ident = append(ident, 1, 2, 0x38, 0x01)
+	ident = appendUint32(ident, 48000)
+	ident = append(ident, 0, 0, 0)
+
+	vc := &vorbisComment{
+		Comments: Comments{
+			Vendor: "test",
+			Fields: comments,
+		},
+	}
+	comment := append([]byte("OpusTags"), vc.bytes()...)
+
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, serial, 0, ident))
+	buf.Write(testPage(0, 0, serial, 1, comment))
+	for i := 0; i < audioPages; i++ {
+		var headerType byte
+		if i == audioPages-1 {
+			headerType = headerTypeEOS
+		}
+		buf.Write(testPage(headerType, 312+int64(i+1)*960, serial, uint32(i+2), make([]byte, 100)))
+	}
+	return buf.Bytes()
+}
+
+// testOggFLAC returns an Ogg FLAC stream with the given comments, followed by the given number of audio
+// pages of 4096 samples each.
+func testOggFLAC(comments []string, audioPages int) []byte {
+	const serial = 9012
+
+	// The STREAMINFO metadata block with its header.
+	ident := append([]byte("\x7fFLAC\x01\x00\x00\x01fLaC"), testFLAC(nil)[4:4+4+34]...)
+
+	vc := &vorbisComment{
+		Comments: Comments{
+			Vendor: "test",
+			Fields: comments,
+		},
+	}
+	data := vc.bytes()
+	comment := []byte{0x80 | flacBlockTypeVorbisComment, byte(len(data) >> 16), byte(len(data) >> 8), byte(len(data))}
+	comment = append(comment, data...)
+
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, serial, 0, ident))
+	buf.Write(testPage(0, 0, serial, 1, comment))
+	for i := 0; i < audioPages; i++ {
+		var headerType byte
+		if i == audioPages-1 {
+			headerType = headerTypeEOS
+		}
+		buf.Write(testPage(headerType, int64(i+1)*4096, serial, uint32(i+2), make([]byte, 100)))
+	}
+	return buf.Bytes()
+}
+
+func TestReadRepeatedTags(t *testing.T) {
+	testCases := []struct {
+		comments []string
+		start    int64
+		length   int64
+	}{
+		{
+			comments: []string{"LOOPSTART=1", "LOOPLENGTH=3"},
+			start:    1,
+			length:   3,
+		},
+		// The first value of each tag makes the primary loop.
+		{
+			comments: []string{"LOOPSTART=1", "LOOPSTART=2", "LOOPLENGTH=3"},
+			start:    1,
+			length:   3,
+		},
+		{
+			comments: []string{"LOOPSTART=1", "LOOPLENGTH=3", "LOOPSTART=2", "LOOPLENGTH=4"},
+			start:    1,
+			length:   3,
+		},
+		// Broken loops other than the primary one are skipped.
+		{
+			comments: []string{"LOOPSTART99999999999999999999=1", "LOOPSTART=100", "LOOPLENGTH=5"},
+			start:    100,
+			length:   5,
+		},
+		{
+			comments: []string{"LOOPSTART=100", "LOOPLENGTH=5", "LOOPSTART1=10", "LOOPEND1=5"},
+			start:    100,
+			length:   5,
+		},
+	}
+	for _, tc := range testCases {
+		start, length, err := Read(bytes.NewReader(testVorbis(tc.comments, 1)))
+		if err != nil {
+			t.Errorf("Read(%q): %v", tc.comments, err)
+			continue
+		}
+		if start != tc.start || length != tc.length {
+			t.Errorf("Read(%q): got (%d, %d), want (%d, %d)", tc.comments, start, length, tc.start, tc.length)
+		}
+		if _, err := ReadInfo(bytes.NewReader(testVorbis(tc.comments, 1))); err != nil {
+			t.Errorf("ReadInfo(%q): %v", tc.comments, err)
+		}
+	}
+}
+
+func TestReadInfoMalformedAuxTags(t *testing.T) {
+	testCases := [][]string{
+		{"LOOPSTART=1", "LOOPLENGTH=2", "LOOPCOUNT=x"},
+		{"LOOPSTART=1", "LOOPLENGTH=2", "LOOPCOUNT=99999999999999999999"},
+		{"LOOPSTART=1", "LOOPLENGTH=2", "LOOPFADE=99999999999"},
+		{"LOOPSTART=1", "LOOPLENGTH=2", "LOOPFADE=-1"},
+	}
+	for _, comments := range testCases {
+		info, err := ReadInfo(bytes.NewReader(testVorbis(comments, 1)))
+		if err != nil {
+			t.Errorf("ReadInfo(%q): %v", comments, err)
+			continue
+		}
+		if info.LoopCount != 0 || info.LoopFade != 0 {
+			t.Errorf("ReadInfo(%q): got LoopCount %d and LoopFade %v, want 0 and 0", comments, info.LoopCount, info.LoopFade)
+		}
+		if info.LoopStart != 1 || info.LoopLength != 2 {
+			t.Errorf("ReadInfo(%q): got (%d, %d), want (1, 2)", comments, info.LoopStart, info.LoopLength)
+		}
+	}
+}
+
+func TestReadTagFields(t *testing.T) {
+	testCases := []struct {
+		comments []string
+		start    int64
+		length   int64
+		err      error
+	}{
+		// The length prefix of the next field is not a part of the value, even when it is an ASCII digit.
+		{
+			comments: []string{"LOOPSTART=1000", "A=" + strings.Repeat("x", 46), "LOOPLENGTH=5"},
+			start:    1000,
+			length:   5,
+		},
+		// A key in the value of another field is not a tag.
+		{
+			comments: []string{"COMMENT=set LOOPSTART=5 later", "LOOPSTART=7", "LOOPLENGTH=3"},
+			start:    7,
+			length:   3,
+		},
+		// A key must be the whole field name.
+		{
+			comments: []string{"MYLOOPSTART=5", "LOOPSTART=7", "LOOPLENGTH=3"},
+			start:    7,
+			length:   3,
+		},
+		// Keys are case-sensitive by default.
+		{
+			comments: []string{"loopstart=5", "LOOPSTART=7", "LOOPLENGTH=3"},
+			start:    7,
+			length:   3,
+		},
+		// A value must be the whole field value.
+		{
+			comments: []string{"LOOPSTART=5x", "LOOPSTART=7", "LOOPLENGTH=3"},
+			start:    7,
+			length:   3,
+		},
+		{
+			comments: []string{"MYLOOPSTART=5", "COMMENT=LOOPLENGTH=3"},
+			err:      ErrNoLoopTags,
+		},
+	}
+	for _, tc := range testCases {
+		start, length, err := Read(bytes.NewReader(testVorbis(tc.comments, 1)))
+		if err != tc.err {
+			t.Errorf("Read(%q): got error %v, want %v", tc.comments, err, tc.err)
+			continue
+		}
+		if start != tc.start || length != tc.length {
+			t.Errorf("Read(%q): got (%d, %d), want (%d, %d)", tc.comments, start, length, tc.start, tc.length)
+		}
+	}
+}
+
+func TestReadTagInVendor(t *testing.T) {
+	ident, _ := testVorbisHeaders(nil)
+	vc := &vorbisComment{
+		Comments: Comments{
+			Vendor: "LOOPSTART=5",
+			Fields: []string{"LOOPSTART=7"},
+		},
+		// The trailer of the packet is not a field either.
+		trailer: []byte("LOOPLENGTH=3\x01"),
+	}
+	comment := append([]byte("\x03vorbis"), vc.bytes()...)
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident))
+	buf.Write(testPage(headerTypeEOS, 0, 1, 1, comment, []byte("\x05vorbis")))
+
+	info, err := ReadInfo(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.LoopStart != 7 || info.HasLoopLength {
+		t.Errorf("got LoopStart %d and HasLoopLength %t, want 7 and false", info.LoopStart, info.HasLoopLength)
+	}
+}
+
+func TestReadCaseInsensitiveKeys(t *testing.T) {
+	comments := []string{"loopstart = 44100", "LoopLength=10", "MYLOOPSTART=5"}
+	d := NewDecoder(bytes.NewReader(testVorbis(comments, 1)), &DecoderOptions{
+		CaseInsensitiveKeys: true,
+		AllowSpaces:         true,
+	})
+	start, length, err := d.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != 44100 || length != 10 {
+		t.Errorf("got (%d, %d), want (44100, 10)", start, length)
+	}
+}
+
+func TestReadInfoHasTags(t *testing.T) {
+	testCases := []struct {
+		comments  []string
+		start     int64
+		length    int64
+		hasStart  bool
+		hasLength bool
+	}{
+		{},
+		{comments: []string{"TITLE=x"}},
+		{comments: []string{"LOOPSTART=0", "LOOPLENGTH=0"}, hasStart: true, hasLength: true},
+		{comments: []string{"LOOPSTART=0"}, hasStart: true},
+		{comments: []string{"LOOPLENGTH=0"}, hasLength: true},
+		{comments: []string{"LOOPSTART=5", "LOOPEND=5"}, start: 5, hasStart: true, hasLength: true},
+	}
+	for _, tc := range testCases {
+		info, err := ReadInfo(bytes.NewReader(testVorbis(tc.comments, 1)))
+		if err != nil {
+			t.Errorf("%q: %v", tc.comments, err)
+			continue
+		}
+		if info.LoopStart != tc.start || info.LoopLength != tc.length || info.HasLoopStart != tc.hasStart || info.HasLoopLength != tc.hasLength {
+			t.Errorf("%q: got (%d, %d, %t, %t), want (%d, %d, %t, %t)", tc.comments, info.LoopStart, info.LoopLength, info.HasLoopStart, info.HasLoopLength, tc.start, tc.length, tc.hasStart, tc.hasLength)
+		}
+		if got, want := info.HasLoopLength, info.LengthTag != LengthTagNone; got != want {
+			t.Errorf("%q: got HasLoopLength %t, want %t", tc.comments, got, want)
+		}
+	}
+
+	// A WAV stream without a smpl chunk has no loop.
+	info, err := ReadWAV(bytes.NewReader(testWAV(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.HasLoopStart || info.HasLoopLength {
+		t.Errorf("ReadWAV: got HasLoopStart %t and HasLoopLength %t, want false and false", info.HasLoopStart, info.HasLoopLength)
+	}
+}
+
+func TestReadInfoChained(t *testing.T) {
+	// Both chains use the same serial number.
+	data := append(testVorbis(nil, 3), testVorbis(nil, 2)...)
+	for _, src := range []io.Reader{bytes.NewReader(data), struct{ io.Reader }{bytes.NewReader(data)}} {
+		info, err := ReadInfo(src)
+		if err != nil {
+			t.Fatalf("ReadInfo(%T): %v", src, err)
+		}
+		if info.TotalSamples != 3000 {
+			t.Errorf("ReadInfo(%T): got TotalSamples %d, want 3000", src, info.TotalSamples)
+		}
+	}
+}
+
+func TestReadInfoTruncated(t *testing.T) {
+	data := testVorbis(nil, 3)
+	// Each audio page is 128 bytes: a 27-byte header, a segment and a 100-byte body.
+	for _, n := range []int{1, 100, 128 + 1, 128 + 100} {
+		truncated := data[:len(data)-n]
+		for _, src := range []io.Reader{bytes.NewReader(truncated), struct{ io.Reader }{bytes.NewReader(truncated)}} {
+			if _, err := ReadInfo(src); err != io.ErrUnexpectedEOF {
+				t.Errorf("ReadInfo(%T) without the last %d bytes: got %v, want %v", src, n, err, io.ErrUnexpectedEOF)
+			}
+		}
+	}
+}
+
+func TestReadEndOfStream(t *testing.T) {
+	ident, comment := testVorbisHeaders([]string{"LOOPSTART=1", "LOOPLENGTH=2"})
+	bos := testPage(headerTypeBOS, 0, 1, 0, ident)
+	eos := testPage(headerTypeEOS, 0, 1, 1, comment, []byte("\x05vorbis"))
+	stream := append(append([]byte{}, bos...), eos...)
+
+	testCases := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{
+			name: "end at a page boundary",
+			data: stream,
+		},
+		{
+			// Read stops after the end-of-stream page.
+			name: "junk after the end of the stream",
+			data: append(append([]byte{}, stream...), "junk"...),
+		},
+		{
+			name: "truncated page",
+			data: stream[:len(stream)-1],
+			err:  io.ErrUnexpectedEOF,
+		},
+		{
+			name: "truncated page header",
+			data: stream[:len(bos)+10],
+			err:  io.ErrUnexpectedEOF,
+		},
+	}
+	for _, tc := range testCases {
+		for _, src := range []io.Reader{bytes.NewReader(tc.data), struct{ io.Reader }{bytes.NewReader(tc.data)}} {
+			start, length, err := Read(src)
+			if err != tc.err {
+				t.Errorf("%s (%T): got %v, want %v", tc.name, src, err, tc.err)
+				continue
+			}
+			if err == nil && (start != 1 || length != 2) {
+				t.Errorf("%s (%T): got (%d, %d), want (1, 2)", tc.name, src, start, length)
+			}
+		}
+	}
+}
+
+func TestReadMissingHeaders(t *testing.T) {
+	ident, _ := testVorbisHeaders(nil)
+	bos := testPage(headerTypeBOS, 0, 1, 0, ident)
+
+	testCases := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{
+			name: "empty",
+			data: nil,
+			err:  ErrNotOgg,
+		},
+		{
+			name: "not Ogg",
+			data: testWAV(nil),
+			err:  ErrNotOgg,
+		},
+		{
+			name: "truncated first page",
+			data: bos[:10],
+			err:  ErrNotOgg,
+		},
+		{
+			name: "no Vorbis stream",
+			data: testPage(headerTypeBOS|headerTypeEOS, 0, 1, 0, []byte("fishead\x00")),
+			err:  ErrNoVorbisStream,
+		},
+		{
+			name: "identification header only",
+			data: bos,
+			err:  ErrNoCommentHeader,
+		},
+		{
+			name: "setup header instead of the comment header",
+			data: append(append([]byte{}, bos...), testPage(headerTypeEOS, 0, 1, 1, []byte("\x05vorbis"))...),
+			err:  ErrNoCommentHeader,
+		},
+	}
+	for _, tc := range testCases {
+		if _, _, err := Read(bytes.NewReader(tc.data)); err != tc.err {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestReadOtherStreams(t *testing.T) {
+	skeleton := testPage(headerTypeBOS, 0, 1, 0, []byte("fishead\x00"))
+	ident, comment := testVorbisHeaders([]string{"LOOPSTART=1", "LOOPLENGTH=2"})
+	otherIdent, otherComment := testVorbisHeaders([]string{"LOOPSTART=3", "LOOPLENGTH=4"})
+
+	// A Skeleton stream comes first, and the tags of the second Vorbis stream are ignored.
+	var buf bytes.Buffer
+	buf.Write(skeleton)
+	buf.Write(testPage(headerTypeBOS, 0, 2, 0, ident))
+	buf.Write(testPage(headerTypeBOS, 0, 3, 0, otherIdent))
+	buf.Write(testPage(headerTypeEOS, 0, 1, 1, []byte("fisbone\x00")))
+	buf.Write(testPage(0, 0, 3, 1, otherComment, []byte("\x05vorbis")))
+	buf.Write(testPage(0, 0, 2, 1, comment, []byte("\x05vorbis")))
+
+	start, length, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != 1 || length != 2 {
+		t.Errorf("got (%d, %d), want (1, 2)", start, length)
+	}
+}
+
+func TestReadOpus(t *testing.T) {
+	data := testOpus([]string{"LOOPSTART=48000", "LOOPLENGTH=96000"}, 2)
+	for _, src := range []io.Reader{bytes.NewReader(data), struct{ io.Reader }{bytes.NewReader(data)}} {
+		start, length, err := Read(src)
+		if err != nil {
+			t.Errorf("Read(%T): %v", src, err)
+			continue
+		}
+		if start != 48000 || length != 96000 {
+			t.Errorf("Read(%T): got (%d, %d), want (48000, 96000)", src, start, length)
+		}
+	}
+
+	// A packet without the full OpusTags signature is not a comment header.
+	ident := data[27+1 : 27+1+19]
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident))
+	buf.Write(testPage(headerTypeEOS, 0, 1, 1, []byte("OpusTag")))
+	if _, _, err := Read(bytes.NewReader(buf.Bytes())); err != ErrNoCommentHeader {
+		t.Errorf("got %v, want %v", err, ErrNoCommentHeader)
+	}
+}
+
+func TestReadComments(t *testing.T) {
+	comments := []string{"TITLE=a", "Artist=b", "title=c", "NOEQUALS", "EMPTY=", "DESCRIPTION=x=y"}
+	for _, data := range [][]byte{testVorbis(comments, 1), testOpus(comments, 1), testOggFLAC(comments, 1)} {
+		got, err := ReadComments(bytes.NewReader(data))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		want := map[string][]string{
+			"TITLE":       {"a", "c"},
+			"ARTIST":      {"b"},
+			"EMPTY":       {""},
+			"DESCRIPTION": {"x=y"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestReadInfoCodecs(t *testing.T) {
+	comments := []string{"LOOPSTART=1", "LOOPLENGTH=2"}
+	testCases := []struct {
+		name       string
+		data       []byte
+		sampleRate int
+		channels   int
+		total      int64
+	}{
+		{
+			name:       "Vorbis",
+			data:       testVorbis(comments, 3),
+			sampleRate: 44100,
+			channels:   2,
+			total:      3000,
+		},
+		{
+			// The pre-skip is not counted.
+			name:       "Opus",
+			data:       testOpus(comments, 3),
+			sampleRate: 48000,
+			channels:   2,
+			total:      3 * 960,
+		},
+		{
+			name:       "headers only",
+			data:       testVorbis(comments, 0),
+			sampleRate: 44100,
+			channels:   2,
+			total:      0,
+		},
+	}
+	for _, tc := range testCases {
+		for _, src := range []io.Reader{bytes.NewReader(tc.data), struct{ io.Reader }{bytes.NewReader(tc.data)}} {
+			info, err := ReadInfo(src)
+			if err != nil {
+				t.Errorf("%s (%T): %v", tc.name, src, err)
+				continue
+			}
+			if info.SampleRate != tc.sampleRate || info.Channels != tc.channels || info.TotalSamples != tc.total {
+				t.Errorf("%s (%T): got %d Hz, %d channels and %d samples, want %d Hz, %d channels and %d samples", tc.name, src, info.SampleRate, info.Channels, info.TotalSamples, tc.sampleRate, tc.channels, tc.total)
+			}
+			if info.LoopStart != 1 || info.LoopLength != 2 {
+				t.Errorf("%s (%T): got (%d, %d), want (1, 2)", tc.name, src, info.LoopStart, info.LoopLength)
+			}
+		}
+	}
+
+	// The granule position -1 means that no packet finishes on the page, and is not the end of the stream.
+	ident, comment := testVorbisHeaders(comments)
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident))
+	buf.Write(testPage(0, 0, 1, 1, comment, []byte("\x05vorbis")))
+	buf.Write(testPage(0, 1000, 1, 2, make([]byte, 100)))
+	buf.Write(testPage(headerTypeEOS, -1, 1, 3, make([]byte, 100)))
+	info, err := ReadInfo(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TotalSamples != 1000 {
+		t.Errorf("got TotalSamples %d, want 1000", info.TotalSamples)
+	}
+
+	// A broken identification header is an error for ReadInfo.
+	buf.Reset()
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident[:20]))
+	buf.Write(testPage(headerTypeEOS, 0, 1, 1, comment, []byte("\x05vorbis")))
+	if _, err := ReadInfo(bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrCorruptPage) {
+		t.Errorf("got %v, want ErrCorruptPage", err)
+	}
+}
+
+func TestReadBrokenComment(t *testing.T) {
+	ident, _ := testVorbisHeaders(nil)
+	field := "LOOPSTART=1"
+	testCases := []struct {
+		name    string
+		comment []byte
+		err     error
+	}{
+		{
+			name:    "no tags",
+			comment: append([]byte("\x03vorbis"), (&vorbisComment{Comments: Comments{Fields: []string{"TITLE=x"}}}).bytes()...),
+			err:     ErrNoLoopTags,
+		},
+		{
+			name:    "too long vendor string",
+			comment: append([]byte("\x03vorbis"), appendUint32(nil, 0xffffffff)...),
+			err:     ErrCorruptPage,
+		},
+		{
+			name:    "no number of comments",
+			comment: append([]byte("\x03vorbis"), appendUint32(nil, 0)...),
+			err:     ErrCorruptPage,
+		},
+		{
+			name:    "too many comments",
+			comment: append(append(append([]byte("\x03vorbis"), appendUint32(nil, 0)...), appendUint32(nil, 0xffffffff)...), append(appendUint32(nil, uint32(len(field))), field...)...),
+			err:     ErrCorruptPage,
+		},
+		{
+			name:    "too long comment",
+			comment: append(append(append([]byte("\x03vorbis"), appendUint32(nil, 0)...), appendUint32(nil, 1)...), append(appendUint32(nil, 100), field...)...),
+			err:     ErrCorruptPage,
+		},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident))
+		buf.Write(testPage(headerTypeEOS, 0, 1, 1, tc.comment, []byte("\x05vorbis")))
+		if _, _, err := Read(bytes.NewReader(buf.Bytes())); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestReadShortPackets(t *testing.T) {
+	ident, comment := testVorbisHeaders([]string{"LOOPSTART=1", "LOOPLENGTH=2"})
+	testCases := []struct {
+		name    string
+		ident   []byte
+		comment []byte
+		err     error
+	}{
+		{name: "empty identification header", ident: []byte{}, err: ErrNoVorbisStream},
+		{name: "only the packet type", ident: []byte{1}, err: ErrNoVorbisStream},
+		{name: "cut codec name", ident: []byte("\x01vo"), err: ErrNoVorbisStream},
+		{name: "empty comment header", ident: ident, comment: []byte{}, err: ErrNoCommentHeader},
+		{name: "cut comment header name", ident: ident, comment: []byte("\x03vo"), err: ErrNoCommentHeader},
+		{name: "no vendor length", ident: ident, comment: []byte("\x03vorbis"), err: ErrCorruptPage},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		buf.Write(testPage(headerTypeBOS, 0, 1, 0, tc.ident))
+		if tc.comment != nil {
+			buf.Write(testPage(headerTypeEOS, 0, 1, 1, tc.comment))
+		}
+		if _, _, err := Read(bytes.NewReader(buf.Bytes())); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+		}
+	}
+
+	// A short packet of another logical stream does not desynchronize the pages after it.
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, 2, 0, []byte("\x01vo")))
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident))
+	buf.Write(testPage(0, 0, 1, 1, comment, []byte("\x05vorbis")))
+	start, length, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Read after a short packet: %v", err)
+	}
+	if start != 1 || length != 2 {
+		t.Errorf("Read after a short packet: got (%d, %d), want (1, 2)", start, length)
+	}
+}
+
+func TestReadCommentSpanningPages(t *testing.T) {
+	comments := []string{"DESCRIPTION=" + strings.Repeat("x", 100000), "LOOPSTART=1", "LOOPLENGTH=2"}
+	ident, comment := testVorbisHeaders(comments)
+	other, _ := testVorbisHeaders(nil)
+
+	var buf bytes.Buffer
+	buf.Write(testPage(headerTypeBOS, 0, 1, 0, ident))
+	buf.Write(testPage(headerTypeBOS, 0, 2, 0, other))
+	pages := paginate([][]byte{comment, []byte("\x05vorbis")}, 1, 1, 0)
+	if len(pages) < 2 {
+		t.Fatalf("got %d pages, want 2 or more", len(pages))
+	}
+	for i, p := range pages {
+		buf.Write(p.bytes())
+		// A page of another logical stream can come between the pages of the packet.
+		if i == 0 {
+			buf.Write(testPage(headerTypeEOS, 0, 2, 1, make([]byte, 100)))
+		}
+	}
+
+	data := buf.Bytes()
+	for _, src := range []io.Reader{bytes.NewReader(data), struct{ io.Reader }{bytes.NewReader(data)}} {
+		start, length, err := Read(src)
+		if err != nil {
+			t.Errorf("Read(%T): %v", src, err)
+			continue
+		}
+		if start != 1 || length != 2 {
+			t.Errorf("Read(%T): got (%d, %d), want (1, 2)", src, start, length)
+		}
+	}
+}
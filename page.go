@@ -0,0 +1,557 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Header type flags of a page.
+// https://www.xiph.org/ogg/doc/framing.html
+const (
+	headerTypeContinued = 0x01
+	headerTypeBOS       = 0x02
+	headerTypeEOS       = 0x04
+)
+
+type page struct {
+	headerType byte
+	granule    int64
+	serial     uint32
+	seq        uint32
+	segments   []byte
+	body       []byte
+
+	// raw is the original encoded page. raw is nil for a page that is not read from a stream, or whose
+	// body is skipped.
+	raw []byte
+}
+
+// maxSegments is the maximum number of segments in a page.
+const maxSegments = 255
+
+// pageReader reads pages from a stream.
+type pageReader struct {
+	r      io.Reader
+	seeker io.Seeker
+
+	// buffered is r when the reader cannot seek, and nil otherwise.
+	buffered *bufio.Reader
+
+	// ctx is checked between pages and between reads of r.
+	ctx context.Context
+
+	verifyCRC        bool
+	skipCorruptPages bool
+	resync           bool
+
+	maxPages       int
+	maxCommentSize int
+	maxBytes       int64
+
+	// serial is the serial number of the logical stream to choose, used only when hasSerial is true.
+	serial    uint32
+	hasSerial bool
+
+	// start is the byte offset where the reader starts.
+	start int64
+
+	// end is the byte offset of the end of the seeker, or -1 when it is not known yet.
+	end int64
+
+	// pending is the bytes to be read again before r, pushed back when resynchronizing or detecting the
+	// format.
+	pending []byte
+
+	// offset is the byte offset of the next page.
+	offset int64
+
+	// pages is the number of the pages read so far.
+	pages int
+
+	// scratch is the buffer for the header and the segment table of a page.
+	scratch [27 + maxSegments]byte
+
+	// skipped is the page whose body is skipped. The page and its segment table, which refers to scratch,
+	// are valid only until the next page is read.
+	skipped page
+}
+
+func newPageReader(r io.Reader, opts *DecoderOptions) *pageReader {
+	pr := &pageReader{
+		r:   r,
+		ctx: context.Background(),
+	}
+	if opts != nil {
+		pr.verifyCRC = opts.VerifyCRC
+		pr.skipCorruptPages = opts.SkipCorruptPages
+		pr.resync = opts.Resync
+		pr.maxPages = opts.MaxPages
+		pr.maxCommentSize = opts.MaxCommentSize
+		pr.maxBytes = opts.MaxBytes
+		pr.serial = opts.Serial
+		pr.hasSerial = opts.HasSerial
+	}
+	// *os.File for a pipe implements io.Seeker but cannot seek. Check that the reader can actually seek.
+	if s, ok := r.(io.Seeker); ok {
+		if offset, err := s.Seek(0, io.SeekCurrent); err == nil {
+			pr.seeker = s
+			pr.offset = offset
+			pr.start = offset
+			pr.end = -1
+		}
+	}
+	// Reading a page takes a few small reads. Buffer them unless the reader can seek, as seeking must
+	// keep the position of the underlying reader.
+	if pr.seeker == nil {
+		pr.buffered = bufio.NewReader(r)
+		pr.r = pr.buffered
+	}
+	return pr
+}
+
+// chooses reports whether the logical stream of the given beginning-of-stream page is the one to read.
+// The first Vorbis, Opus or FLAC stream is chosen unless the serial number is specified.
+func (pr *pageReader) chooses(p *page) bool {
+	if detectCodec(p.body) == 0 {
+		return false
+	}
+	return !pr.hasSerial || p.serial == pr.serial
+}
+
+// remainingBytes returns the number of bytes the reader can still read, or -1 when there is no limit.
+func (pr *pageReader) remainingBytes() int64 {
+	if pr.maxBytes == 0 {
+		return -1
+	}
+	return pr.maxBytes - (pr.offset - pr.start)
+}
+
+// checkBytes returns an error if reading n more bytes exceeds the limit.
+func (pr *pageReader) checkBytes(n int64) error {
+	if r := pr.remainingBytes(); r >= 0 && n > r {
+		return fmt.Errorf("%w: more than %d bytes", ErrLimitExceeded, pr.maxBytes)
+	}
+	return nil
+}
+
+// checkCommentSize returns an error if a comment header packet of n bytes exceeds the limit.
+func (pr *pageReader) checkCommentSize(n int) error {
+	if pr.maxCommentSize > 0 && n > pr.maxCommentSize {
+		return fmt.Errorf("%w: comment header larger than %d bytes", ErrLimitExceeded, pr.maxCommentSize)
+	}
+	return nil
+}
+
+// next reads the next page.
+// next returns io.EOF only when the stream ends at a page boundary.
+//
+// If skipBody is not nil and returns true for the page, next skips the page body and the returned page
+// has neither the body nor the raw bytes. Such a page is valid only until the next call of next. When the
+// checksum is verified, the body is always read.
+func (pr *pageReader) next(skipBody func(p *page) bool) (*page, error) {
+	for {
+		if err := pr.ctx.Err(); err != nil {
+			return nil, err
+		}
+		if pr.maxPages > 0 && pr.pages >= pr.maxPages {
+			return nil, fmt.Errorf("%w: more than %d pages", ErrLimitExceeded, pr.maxPages)
+		}
+		offset := pr.offset
+		p, err := pr.readPage(skipBody)
+		if err != nil {
+			// A stream that does not start with a whole page is not an Ogg stream.
+			if pr.pages == 0 && (err == io.ErrUnexpectedEOF || errors.Is(err, ErrCorruptPage)) {
+				return nil, ErrNotOgg
+			}
+			return nil, err
+		}
+		if p.raw != nil && pr.verifyCRC {
+			if binary.LittleEndian.Uint32(p.raw[22:26]) != pageCRC(p.raw) {
+				if pr.resync {
+					// The capture pattern might be in junk by chance. Scan again from the next byte.
+					pr.pending = append(p.raw[1:len(p.raw):len(p.raw)], pr.pending...)
+					pr.offset = offset + 1
+					continue
+				}
+				if pr.skipCorruptPages {
+					continue
+				}
+				return nil, fmt.Errorf("%w: checksum mismatch at offset %d", ErrCorruptPage, offset)
+			}
+		}
+		pr.pages++
+		return p, nil
+	}
+}
+
+// readFull reads exactly len(b) bytes. readFull returns io.EOF only when no bytes are read.
+func (pr *pageReader) readFull(b []byte) error {
+	n := copy(b, pr.pending)
+	pr.pending = pr.pending[n:]
+	if n == len(b) {
+		return nil
+	}
+	// Check ctx between reads as well as between pages, as a page can be read by many small reads from a
+	// slow reader.
+	for n < len(b) {
+		if err := pr.ctx.Err(); err != nil {
+			return err
+		}
+		m, err := pr.r.Read(b[n:])
+		n += m
+		if n == len(b) {
+			break
+		}
+		if err == io.EOF && n > 0 {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discard skips n bytes.
+func (pr *pageReader) discard(n int64) error {
+	m := int64(len(pr.pending))
+	if m > n {
+		m = n
+	}
+	pr.pending = pr.pending[m:]
+	n -= m
+	if n == 0 {
+		return nil
+	}
+	if pr.seeker != nil {
+		pos, err := pr.seeker.Seek(n, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		// Seeking past the end is not an error. Check the end so that a truncated page is reported in
+		// the same way as reading it.
+		if pr.end < 0 {
+			end, err := pr.seeker.Seek(0, io.SeekEnd)
+			if err != nil {
+				return err
+			}
+			if _, err := pr.seeker.Seek(pos, io.SeekStart); err != nil {
+				return err
+			}
+			pr.end = end
+		}
+		if pos > pr.end {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	}
+	// Discard of bufio.Reader does not allocate, unlike io.CopyN.
+	for n > 0 {
+		m := n
+		if m > math.MaxInt32 {
+			m = math.MaxInt32
+		}
+		if _, err := pr.buffered.Discard(int(m)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		n -= m
+	}
+	return nil
+}
+
+// readBytes reads n bytes that are not a page, e.g. a header of a file that is not Ogg.
+func (pr *pageReader) readBytes(n int) ([]byte, error) {
+	if err := pr.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := pr.checkBytes(int64(n)); err != nil {
+		return nil, err
+	}
+	// The size usually comes from the stream. Grow the buffer as the bytes are actually read, so that a
+	// broken or malicious size does not allocate a huge buffer for a short stream.
+	const initialSize = 64 * 1024
+	c := n
+	if c > initialSize {
+		c = initialSize
+	}
+	b := make([]byte, 0, c)
+	for len(b) < n {
+		if len(b) == cap(b) {
+			c := 2 * cap(b)
+			if c > n {
+				c = n
+			}
+			nb := make([]byte, len(b), c)
+			copy(nb, b)
+			b = nb
+		}
+		if err := pr.readFull(b[len(b):cap(b)]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		b = b[:cap(b)]
+	}
+	pr.offset += int64(n)
+	return b, nil
+}
+
+// readBytesUpTo reads at most n bytes that are not a page. readBytesUpTo returns fewer bytes only at the
+// end of the stream or at the limit of the bytes to read.
+func (pr *pageReader) readBytesUpTo(n int) ([]byte, error) {
+	if r := pr.remainingBytes(); r >= 0 && int64(n) > r {
+		if r == 0 {
+			return nil, pr.checkBytes(int64(n))
+		}
+		n = int(r)
+	}
+	b := make([]byte, n)
+	m := copy(b, pr.pending)
+	pr.pending = pr.pending[m:]
+	for m < n {
+		if err := pr.ctx.Err(); err != nil {
+			return nil, err
+		}
+		k, err := pr.r.Read(b[m:])
+		m += k
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	pr.offset += int64(m)
+	return b[:m], nil
+}
+
+// unreadBytes pushes back b, the bytes read last by readBytes, so that they are read again.
+func (pr *pageReader) unreadBytes(b []byte) {
+	pr.pending = append(b[:len(b):len(b)], pr.pending...)
+	pr.offset -= int64(len(b))
+}
+
+// skipBytes skips n bytes that are not a page.
+func (pr *pageReader) skipBytes(n int64) error {
+	if err := pr.ctx.Err(); err != nil {
+		return err
+	}
+	if err := pr.checkBytes(n); err != nil {
+		return err
+	}
+	if err := pr.discard(n); err != nil {
+		return err
+	}
+	pr.offset += n
+	return nil
+}
+
+func (pr *pageReader) readPage(skipBody func(p *page) bool) (*page, error) {
+	header := pr.scratch[:27]
+	if err := pr.readFull(header); err != nil {
+		return nil, err
+	}
+	// Check the limit after reading the header so that a stream ending at the limit is not an error.
+	if err := pr.checkBytes(int64(len(header))); err != nil {
+		return nil, err
+	}
+	for {
+		var err error
+		if string(header[:4]) != "OggS" {
+			err = fmt.Errorf("%w: invalid capture pattern %q at offset %d", ErrCorruptPage, header[:4], pr.offset)
+		} else if header[4] != 0 {
+			err = fmt.Errorf("%w: unsupported page version %d at offset %d", ErrCorruptPage, header[4], pr.offset)
+		} else {
+			break
+		}
+		if !pr.resync {
+			return nil, err
+		}
+
+		// Scan forward for the next capture pattern. Keep the last bytes, which might be the beginning
+		// of a capture pattern.
+		n := bytes.Index(header[1:], []byte("OggS")) + 1
+		if n == 0 {
+			n = len(header) - 3
+		}
+		if err := pr.checkBytes(int64(len(header) + n)); err != nil {
+			return nil, err
+		}
+		copy(header, header[n:])
+		if err := pr.readFull(header[len(header)-n:]); err != nil {
+			// The stream ends without another page.
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			return nil, err
+		}
+		pr.offset += int64(n)
+	}
+
+	nseg := int(header[26])
+	if err := pr.checkBytes(int64(27 + nseg)); err != nil {
+		return nil, err
+	}
+	segments := pr.scratch[27 : 27+nseg]
+	if err := pr.readFull(segments); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	size := 0
+	for _, s := range segments {
+		size += int(s)
+	}
+	if err := pr.checkBytes(int64(27 + nseg + size)); err != nil {
+		return nil, err
+	}
+	pr.offset += int64(27 + nseg + size)
+
+	pr.skipped = page{
+		headerType: header[5],
+		granule:    int64(binary.LittleEndian.Uint64(header[6:14])),
+		serial:     binary.LittleEndian.Uint32(header[14:18]),
+		seq:        binary.LittleEndian.Uint32(header[18:22]),
+		segments:   segments,
+	}
+
+	if skipBody != nil && !pr.verifyCRC && skipBody(&pr.skipped) {
+		if err := pr.discard(int64(size)); err != nil {
+			return nil, err
+		}
+		return &pr.skipped, nil
+	}
+
+	// Allocate the page only when its body is read. The page refers to its own copy of the header and the
+	// segment table.
+	p := pr.skipped
+	raw := make([]byte, 27+nseg+size)
+	copy(raw, pr.scratch[:27+nseg])
+	p.segments = raw[27 : 27+nseg]
+	if err := pr.readFull(raw[27+nseg:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	p.body = raw[27+nseg:]
+	p.raw = raw
+	return &p, nil
+}
+
+// size returns the size of the encoded page in bytes.
+func (p *page) size() int64 {
+	size := int64(27 + len(p.segments))
+	for _, s := range p.segments {
+		size += int64(s)
+	}
+	return size
+}
+
+// bytes returns the encoded page with a fresh checksum.
+func (p *page) bytes() []byte {
+	buf := make([]byte, 27, 27+len(p.segments)+len(p.body))
+	copy(buf, "OggS")
+	buf[5] = p.headerType
+	binary.LittleEndian.PutUint64(buf[6:14], uint64(p.granule))
+	binary.LittleEndian.PutUint32(buf[14:18], p.serial)
+	binary.LittleEndian.PutUint32(buf[18:22], p.seq)
+	buf[26] = byte(len(p.segments))
+	buf = append(buf, p.segments...)
+	buf = append(buf, p.body...)
+	binary.LittleEndian.PutUint32(buf[22:26], pageCRC(buf))
+	return buf
+}
+
+// paginate lays out the given header packets on new pages of the logical stream serial.
+// The first page has the sequence number seq, and the last packet ends the last page.
+//
+// If pageCount is positive and the packets have enough segments, paginate splits the packets into exactly
+// pageCount pages, so that the following pages need not be renumbered. Otherwise, paginate uses as few
+// pages as possible.
+func paginate(packets [][]byte, serial uint32, seq uint32, pageCount int) []*page {
+	type segment struct {
+		data []byte
+
+		// last reports whether the segment ends a packet.
+		last bool
+	}
+	var segments []segment
+	for _, pkt := range packets {
+		for {
+			// A packet whose size is a multiple of 255 ends with a zero-sized segment.
+			n := len(pkt)
+			if n > 255 {
+				n = 255
+			}
+			segments = append(segments, segment{
+				data: pkt[:n],
+				last: n < 255,
+			})
+			pkt = pkt[n:]
+			if n < 255 {
+				break
+			}
+		}
+	}
+
+	if minCount := (len(segments) + 254) / 255; pageCount < minCount || pageCount > len(segments) {
+		pageCount = minCount
+	}
+
+	pages := make([]*page, 0, pageCount)
+	total := len(segments)
+	continued := false
+	for i := 0; i < pageCount; i++ {
+		// Distribute the segments to the pages as evenly as possible.
+		n := total / pageCount
+		if i < total%pageCount {
+			n++
+		}
+		p := &page{
+			granule: -1,
+			serial:  serial,
+			seq:     seq + uint32(i),
+		}
+		if continued {
+			p.headerType = headerTypeContinued
+		}
+		for _, s := range segments[:n] {
+			p.segments = append(p.segments, byte(len(s.data)))
+			p.body = append(p.body, s.data...)
+			if s.last {
+				// Header pages have the granule position 0 once a packet finishes on them.
+				p.granule = 0
+			}
+			continued = !s.last
+		}
+		segments = segments[n:]
+		pages = append(pages, p)
+	}
+	return pages
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"io"
+)
+
+// Report is the meta data of a stream returned by Probe. Report is meant to be marshaled to JSON, e.g. by
+// tools listing audio files.
+type Report struct {
+	// Format is the format of the stream, one of the strings of Format, e.g. "Ogg".
+	Format string `json:"format"`
+
+	// Size is the size of the stream in bytes. Size is 0 when it is unknown.
+	Size int64 `json:"size,omitempty"`
+
+	// Streams is the logical streams. For an Ogg stream, Streams is all the Vorbis, Opus and FLAC logical
+	// streams in the same order as ReadAll. For the other formats, Streams has one stream.
+	Streams []StreamReport `json:"streams"`
+}
+
+// StreamReport is the meta data of a logical stream.
+type StreamReport struct {
+	// Chain is the index of the chain the logical stream belongs to. Chain is 0 except for Ogg.
+	Chain int `json:"chain"`
+
+	// Serial is the serial number of the logical stream. Serial is 0 except for Ogg.
+	Serial uint32 `json:"serial"`
+
+	// Codec is the codec of the logical stream: "Vorbis", "Opus", "FLAC" or "MP3".
+	// Codec is empty for WAV.
+	Codec string `json:"codec,omitempty"`
+
+	// SampleRate is the sample rate in Hz.
+	SampleRate int `json:"sampleRate"`
+
+	// Channels is the number of channels.
+	Channels int `json:"channels"`
+
+	// TotalSamples is the number of PCM samples per channel in the same way as Info.TotalSamples.
+	TotalSamples int64 `json:"totalSamples"`
+
+	// Duration is the duration in seconds.
+	Duration float64 `json:"duration"`
+
+	// Bitrate is the average bitrate in bits per second, estimated from the size of the pages of the
+	// logical stream for Ogg, and from the size of the stream for the other formats.
+	// Bitrate is 0 when it is unknown.
+	Bitrate int64 `json:"bitrate,omitempty"`
+
+	// Vendor is the vendor string of the comment header.
+	Vendor string `json:"vendor,omitempty"`
+
+	// Comments is all the comments in the same way as ReadComments.
+	// For MP3, Comments is the ID3v2 TXXX frames.
+	Comments map[string][]string `json:"comments,omitempty"`
+
+	// Loops is all the loop regions in the same way as Info.Loops. The first one is the primary loop.
+	Loops []LoopReport `json:"loops,omitempty"`
+
+	// LoopCount is the LOOPCOUNT value, or 0 when the tag does not exist or is malformed.
+	LoopCount int `json:"loopCount,omitempty"`
+
+	// LoopFade is the LOOPFADE value in seconds, or 0 when the tag does not exist or is malformed.
+	LoopFade float64 `json:"loopFade,omitempty"`
+}
+
+// LoopReport is a loop region in a StreamReport.
+type LoopReport struct {
+	// Index is the index of the tags, or -1 for the tags without an index.
+	Index int `json:"index"`
+
+	// Start is the LOOPSTART value.
+	Start int64 `json:"start"`
+
+	// HasStart reports whether LOOPSTART exists.
+	HasStart bool `json:"hasStart"`
+
+	// Length is the LOOPLENGTH value, or the length computed from the LOOPEND value.
+	Length int64 `json:"length"`
+
+	// LengthTag is the tag from which Length comes: "LOOPLENGTH" or "LOOPEND".
+	// LengthTag is empty when there is neither LOOPLENGTH nor LOOPEND.
+	LengthTag string `json:"lengthTag,omitempty"`
+}
+
+// Probe detects the format of the given src in the same way as ReadAny, and returns a Report of all the
+// meta data of the stream.
+//
+// Unlike ReadAny, Probe reads the whole of an Ogg stream in the same way as ReadAll to report all the
+// logical streams. For the other formats, Probe stops reading in the same way as ReadFLAC, ReadWAV and
+// ReadMP3, and seeks to the end of src to know the size if src is an io.Seeker.
+func Probe(src io.Reader) (*Report, error) {
+	return NewDecoder(src, nil).Probe()
+}
+
+func probe(pr *pageReader, tags *tagMatcher) (*Report, error) {
+	f, err := detect(pr)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{
+		Format: f.String(),
+	}
+
+	if f == FormatOgg {
+		results, streams, err := readChains(pr, tags)
+		if err != nil {
+			return nil, err
+		}
+		r.Size = pr.offset - pr.start
+		for i, res := range results {
+			s := streams[i]
+			data, _ := commentData(s.codec, s.comment)
+			vc, err := parseComment(data)
+			if err != nil {
+				return nil, err
+			}
+			sr := newStreamReport(res.Info, s.size)
+			sr.Chain = res.Chain
+			sr.Serial = res.Serial
+			sr.Codec = s.codec.String()
+			sr.Vendor = vc.Vendor
+			sr.Comments = commentMap(vc.Fields)
+			r.Streams = append(r.Streams, sr)
+		}
+		return r, nil
+	}
+
+	var (
+		info      Info
+		codecName string
+		vc        *vorbisComment
+	)
+	switch f {
+	case FormatFLAC:
+		var comment []byte
+		info, comment, err = readFLAC(pr, tags)
+		if err != nil {
+			return nil, err
+		}
+		codecName = "FLAC"
+		vc, err = parseComment(comment)
+		if err != nil {
+			return nil, err
+		}
+	case FormatWAV:
+		info, err = readWAV(pr)
+		if err != nil {
+			return nil, err
+		}
+	case FormatMP3:
+		var comment []byte
+		info, comment, err = readMP3(pr, tags)
+		if err != nil {
+			return nil, err
+		}
+		codecName = "MP3"
+		vc = &vorbisComment{
+			Comments: Comments{
+				Fields: id3Fields(comment),
+			},
+		}
+	}
+
+	// Only a seeker tells the size without reading the rest of the stream.
+	if pr.seeker != nil {
+		end, err := pr.seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+		r.Size = end - pr.start
+	}
+	sr := newStreamReport(info, r.Size)
+	sr.Codec = codecName
+	if vc != nil {
+		sr.Vendor = vc.Vendor
+		sr.Comments = commentMap(vc.Fields)
+	}
+	r.Streams = append(r.Streams, sr)
+	return r, nil
+}
+
+// newStreamReport returns a StreamReport with the values of info. size is the number of bytes from which
+// the bitrate is estimated, or 0 when it is unknown.
+func newStreamReport(info Info, size int64) StreamReport {
+	sr := StreamReport{
+		SampleRate:   info.SampleRate,
+		Channels:     info.Channels,
+		TotalSamples: info.TotalSamples,
+		Duration:     info.TotalDuration().Seconds(),
+		LoopCount:    info.LoopCount,
+		LoopFade:     info.LoopFade.Seconds(),
+	}
+	if size > 0 && sr.Duration > 0 {
+		sr.Bitrate = int64(float64(size) * 8 / sr.Duration)
+	}
+	for _, l := range info.Loops {
+		lr := LoopReport{
+			Index:    l.Index,
+			Start:    l.Start,
+			HasStart: l.HasStart,
+			Length:   l.Length,
+		}
+		switch l.LengthTag {
+		case LengthTagLoopLength:
+			lr.LengthTag = "LOOPLENGTH"
+		case LengthTagLoopEnd:
+			lr.LengthTag = "LOOPEND"
+		}
+		sr.Loops = append(sr.Loops, lr)
+	}
+	return sr
+}
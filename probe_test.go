@@ -0,0 +1,134 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	vorbis := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2", "TITLE=a"}, 2)
+	data := append(vorbis, testOpus([]string{"LOOPSTART=3", "LOOPEND=9"}, 1)...)
+	r, err := Probe(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if r.Format != "Ogg" || r.Size != int64(len(data)) || len(r.Streams) != 2 {
+		t.Fatalf("Probe: got (%q, %d, %d streams), want (%q, %d, 2 streams)", r.Format, r.Size, len(r.Streams), "Ogg", len(data))
+	}
+	for i, want := range []StreamReport{
+		{
+			Chain:        0,
+			Serial:       1234,
+			Codec:        "Vorbis",
+			SampleRate:   44100,
+			Channels:     2,
+			TotalSamples: 2000,
+			Vendor:       "test",
+			Comments:     map[string][]string{"LOOPSTART": {"1"}, "LOOPLENGTH": {"2"}, "TITLE": {"a"}},
+			Loops:        []LoopReport{{Index: -1, Start: 1, HasStart: true, Length: 2, LengthTag: "LOOPLENGTH"}},
+		},
+		{
+			Chain:        1,
+			Serial:       5678,
+			Codec:        "Opus",
+			SampleRate:   48000,
+			Channels:     2,
+			TotalSamples: 960,
+			Vendor:       "test",
+			Comments:     map[string][]string{"LOOPSTART": {"3"}, "LOOPEND": {"9"}},
+			Loops:        []LoopReport{{Index: -1, Start: 3, HasStart: true, Length: 6, LengthTag: "LOOPEND"}},
+		},
+	} {
+		got := r.Streams[i]
+		if got.Duration <= 0 || got.Bitrate <= 0 {
+			t.Errorf("Probe: stream %d: got the duration %v and the bitrate %d, want positive values", i, got.Duration, got.Bitrate)
+		}
+		got.Duration = 0
+		got.Bitrate = 0
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Probe: stream %d: got %+v, want %+v", i, got, want)
+		}
+	}
+	// The bitrate of a logical stream is estimated from its own pages.
+	if got, want := r.Streams[1].Bitrate, int64(float64(len(data)-len(vorbis))*8/0.02); got != want {
+		t.Errorf("Probe: got the Opus bitrate %d, want %d", got, want)
+	}
+}
+
+func TestProbeSize(t *testing.T) {
+	for _, data := range [][]byte{
+		testFLAC([]string{"LOOPSTART=1", "LOOPCOUNT=2", "LOOPFADE=1.5"}),
+		testWAV([][2]uint32{{100, 899}}),
+	} {
+		r, err := Probe(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Probe: %v", err)
+		}
+		if r.Size != int64(len(data)) || len(r.Streams) != 1 || r.Streams[0].Bitrate == 0 {
+			t.Errorf("Probe(%s): got the size %d and %d streams, want %d and 1 stream with a bitrate", r.Format, r.Size, len(r.Streams), len(data))
+		}
+
+		// Without an io.Seeker, the size and the bitrate are unknown except for Ogg.
+		r, err = Probe(struct{ io.Reader }{bytes.NewReader(data)})
+		if err != nil {
+			t.Fatalf("Probe: %v", err)
+		}
+		if r.Size != 0 || r.Streams[0].Bitrate != 0 {
+			t.Errorf("Probe(%s) without an io.Seeker: got the size %d and the bitrate %d, want 0 and 0", r.Format, r.Size, r.Streams[0].Bitrate)
+		}
+	}
+
+	if _, err := Probe(bytes.NewReader([]byte("not audio"))); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Probe: got %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestProbeJSON(t *testing.T) {
+	data := testFLAC([]string{"LOOPSTART=1", "LOOPCOUNT=2", "LOOPFADE=1.5"})
+	r, err := Probe(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	s := got["streams"].([]interface{})[0].(map[string]interface{})
+	if got["format"] != "FLAC" || s["codec"] != "FLAC" || s["sampleRate"] != 44100.0 || s["loopCount"] != 2.0 || s["loopFade"] != 1.5 {
+		t.Errorf("json.Marshal: got %s", b)
+	}
+	// A loop with neither LOOPLENGTH nor LOOPEND omits the length tag.
+	if _, ok := s["loops"].([]interface{})[0].(map[string]interface{})["lengthTag"]; ok {
+		t.Errorf("json.Marshal: got %s, want no lengthTag", b)
+	}
+
+	var r2 Report
+	if err := json.Unmarshal(b, &r2); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(&r2, r) {
+		t.Errorf("json.Unmarshal: got %+v, want %+v", &r2, r)
+	}
+}
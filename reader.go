@@ -0,0 +1,241 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"fmt"
+	"io"
+)
+
+// Page is an Ogg page.
+// https://www.xiph.org/ogg/doc/framing.html
+type Page struct {
+	// HeaderType is the header type flags of the page.
+	HeaderType byte
+
+	// Granule is the granule position of the page. Granule is -1 when no packet finishes on the page.
+	Granule int64
+
+	// Serial is the serial number of the logical stream the page belongs to.
+	Serial uint32
+
+	// Sequence is the page sequence number in the logical stream.
+	Sequence uint32
+
+	// Segments is the lacing values of the segment table.
+	Segments []byte
+
+	// Body is the page body.
+	Body []byte
+}
+
+// Continued reports whether the page continues the packet on the previous page.
+func (p *Page) Continued() bool {
+	return p.HeaderType&headerTypeContinued != 0
+}
+
+// BOS reports whether the page is the first page of the logical stream.
+func (p *Page) BOS() bool {
+	return p.HeaderType&headerTypeBOS != 0
+}
+
+// EOS reports whether the page is the last page of the logical stream.
+func (p *Page) EOS() bool {
+	return p.HeaderType&headerTypeEOS != 0
+}
+
+// PageReader reads Ogg pages from a stream.
+// PageReader handles the options of DecoderOptions that apply to pages, e.g. VerifyCRC and Resync, in the
+// same way as Decoder.
+type PageReader struct {
+	pr *pageReader
+}
+
+// NewPageReader creates a new PageReader for r.
+//
+// If opts is nil, the default options are used.
+func NewPageReader(r io.Reader, opts *DecoderOptions) *PageReader {
+	return &PageReader{
+		pr: newPageReader(r, opts),
+	}
+}
+
+// NextPage reads the next page.
+// NextPage returns io.EOF when the stream ends at a page boundary.
+func (r *PageReader) NextPage() (*Page, error) {
+	p, err := r.pr.next(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{
+		HeaderType: p.headerType,
+		Granule:    p.granule,
+		Serial:     p.serial,
+		Sequence:   p.seq,
+		Segments:   p.segments,
+		Body:       p.body,
+	}, nil
+}
+
+// packetReader reassembles the packets of the logical streams from the pages of a pageReader.
+// PacketReader and the functions reading the header packets are built on packetReader.
+type packetReader struct {
+	pr *pageReader
+
+	// partial maps the serial numbers to the packets continuing to the next pages.
+	partial map[uint32][]byte
+
+	// packets is the buffer for the packets finishing on the last page.
+	packets [][]byte
+}
+
+func newPacketReader(pr *pageReader) *packetReader {
+	return &packetReader{
+		pr:      pr,
+		partial: map[uint32][]byte{},
+	}
+}
+
+// next reads the next page in the same way as pageReader.next and returns it with the packets finishing on
+// it. The returned slice is valid only until the next call, but the packets themselves stay valid.
+//
+// When the body of a page is skipped, no packets are returned and the packet continuing from the previous
+// page of the logical stream is discarded.
+//
+// next returns an error wrapping ErrCorruptPage when the continued flag of a page does not match the
+// previous page of the logical stream.
+func (r *packetReader) next(skipBody func(p *page) bool) (*page, [][]byte, error) {
+	p, err := r.pr.next(skipBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.packets = r.packets[:0]
+
+	// A beginning-of-stream page starts a new logical stream, even if a previous chain uses the same
+	// serial number.
+	if p.headerType&headerTypeBOS != 0 {
+		delete(r.partial, p.serial)
+	}
+	if p == &r.pr.skipped {
+		delete(r.partial, p.serial)
+		return p, nil, nil
+	}
+
+	// A packet can span multiple pages. The continued flag tells whether the page continues the packet
+	// on the previous page.
+	// https://www.xiph.org/ogg/doc/framing.html
+	packet, ok := r.partial[p.serial]
+	if continued := p.headerType&headerTypeContinued != 0; len(p.segments) > 0 && continued != ok {
+		return nil, nil, fmt.Errorf("%w: unexpected continued flag: %t", ErrCorruptPage, continued)
+	}
+	delete(r.partial, p.serial)
+
+	body := p.body
+	// n is the size of the segments of the current packet on the page.
+	var n int
+	for _, s := range p.segments {
+		n += int(s)
+		if s == 255 {
+			continue
+		}
+		// A segment shorter than 255 bytes finishes the packet. A packet within the page refers to the
+		// body without copying.
+		if packet != nil {
+			packet = append(packet, body[:n]...)
+		} else {
+			packet = body[:n:n]
+		}
+		r.packets = append(r.packets, packet)
+		body = body[n:]
+		n = 0
+		packet = nil
+	}
+	if packet != nil || n > 0 {
+		r.partial[p.serial] = append(packet, body[:n]...)
+	}
+	return p, r.packets, nil
+}
+
+// partialSize returns the size of the packet of the given logical stream continuing to the next page.
+func (r *packetReader) partialSize(serial uint32) int {
+	return len(r.partial[serial])
+}
+
+// Packet is an Ogg packet.
+type Packet struct {
+	// Serial is the serial number of the logical stream the packet belongs to.
+	Serial uint32
+
+	// Data is the packet data.
+	Data []byte
+
+	// Granule is the granule position of the page on which the packet finishes, if the packet is the last
+	// one finishing on the page. Otherwise, Granule is -1.
+	Granule int64
+
+	// BOS reports whether the packet is the first packet of the logical stream.
+	BOS bool
+
+	// EOS reports whether the packet is the last packet of the logical stream.
+	EOS bool
+}
+
+// PacketReader reads Ogg packets from a stream, reassembling the packets spanning multiple pages.
+// The packets of multiplexed logical streams are returned in the order in which they finish.
+type PacketReader struct {
+	r *packetReader
+
+	packets []*Packet
+}
+
+// NewPacketReader creates a new PacketReader for r.
+//
+// If opts is nil, the default options are used.
+func NewPacketReader(r io.Reader, opts *DecoderOptions) *PacketReader {
+	return &PacketReader{
+		r: newPacketReader(newPageReader(r, opts)),
+	}
+}
+
+// NextPacket reads the next packet.
+// NextPacket returns io.EOF when the stream ends. A packet not finished at the end of the stream is
+// discarded.
+//
+// NextPacket returns an error wrapping ErrCorruptPage when the continued flag of a page does not match
+// the previous page of the logical stream.
+func (r *PacketReader) NextPacket() (*Packet, error) {
+	for len(r.packets) == 0 {
+		p, packets, err := r.r.next(nil)
+		if err != nil {
+			return nil, err
+		}
+		for i, data := range packets {
+			r.packets = append(r.packets, &Packet{
+				Serial:  p.serial,
+				Data:    data,
+				Granule: -1,
+				BOS:     p.headerType&headerTypeBOS != 0 && i == 0,
+			})
+		}
+		if n := len(r.packets); n > 0 {
+			r.packets[n-1].Granule = p.granule
+			r.packets[n-1].EOS = p.headerType&headerTypeEOS != 0
+		}
+	}
+
+	packet := r.packets[0]
+	r.packets = r.packets[1:]
+	return packet, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestPacketReaderSpanningPages(t *testing.T) {
+	packet := bytes.Repeat([]byte("x"), 600)
+	var buf bytes.Buffer
+	// The first page has the first 510 bytes of the packet, and the second page has the rest and a packet.
+	buf.Write((&page{granule: -1, segments: []byte{255, 255}, body: packet[:510]}).bytes())
+	buf.Write((&page{headerType: headerTypeContinued | headerTypeEOS, granule: 100, seq: 1, segments: []byte{90, 3}, body: append(packet[510:], "abc"...)}).bytes())
+
+	r := NewPacketReader(&buf, nil)
+	p, err := r.NextPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p.Data, packet) || p.BOS || p.EOS || p.Granule != -1 {
+		t.Errorf("got a packet of %d bytes (BOS %t, EOS %t, granule %d)", len(p.Data), p.BOS, p.EOS, p.Granule)
+	}
+	p, err = r.NextPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p.Data) != "abc" || p.BOS || !p.EOS || p.Granule != 100 {
+		t.Errorf("got %q (BOS %t, EOS %t, granule %d)", p.Data, p.BOS, p.EOS, p.Granule)
+	}
+	if _, err := r.NextPacket(); err != io.EOF {
+		t.Errorf("got %v, want io.EOF", err)
+	}
+}
+
+func TestPacketReaderUnexpectedContinued(t *testing.T) {
+	page := (&page{headerType: headerTypeBOS | headerTypeContinued, segments: []byte{3}, body: []byte("abc")}).bytes()
+	if _, err := NewPacketReader(bytes.NewReader(page), nil).NextPacket(); !errors.Is(err, ErrCorruptPage) {
+		t.Errorf("got %v, want ErrCorruptPage", err)
+	}
+}
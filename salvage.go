@@ -0,0 +1,92 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strconv"
+)
+
+var salvagePattern = &tagPattern{
+	keys: []string{"LOOPSTART", "LOOPLENGTH", "LOOPEND"},
+}
+
+// Confidence represents how likely a salvaged candidate is a real loop tag.
+type Confidence int
+
+const (
+	// ConfidenceLow means that only the key and the value were found.
+	ConfidenceLow Confidence = iota
+
+	// ConfidenceMedium means that the key and the value form a Vorbis comment field with a matching length.
+	ConfidenceMedium
+
+	// ConfidenceHigh means that the field also follows a Vorbis comment header signature.
+	ConfidenceHigh
+)
+
+// Candidate is a loop tag found by Salvage.
+type Candidate struct {
+	// Offset is the byte offset of the tag in the stream.
+	Offset int64
+
+	// Key is one of "LOOPSTART", "LOOPLENGTH" and "LOOPEND".
+	Key string
+
+	// Value is the tag value.
+	Value int64
+
+	// Confidence is how likely the candidate is a real loop tag.
+	Confidence Confidence
+}
+
+// Salvage scans the whole of the given src for loop tags without parsing Ogg pages.
+// Salvage is for a stream whose pages are too damaged for Read.
+//
+// Salvage returns the candidates in the order of their offsets.
+// Salvage returns an error only when IO error happens.
+func Salvage(src io.Reader) ([]Candidate, error) {
+	buf, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := bytes.Index(buf, []byte("\x03vorbis"))
+
+	var cs []Candidate
+	for _, m := range salvagePattern.findAll(buf) {
+		v, err := strconv.ParseInt(string(m.value), 10, 64)
+		if err != nil {
+			// The value is too big to be a plausible sample position.
+			continue
+		}
+		c := Candidate{
+			Offset: int64(m.pos),
+			Key:    m.key,
+			Value:  v,
+		}
+		// A Vorbis comment field is prefixed with its length in little endian.
+		if m.pos >= 4 && int(binary.LittleEndian.Uint32(buf[m.pos-4:m.pos])) == m.end-m.pos {
+			c.Confidence = ConfidenceMedium
+			if sig >= 0 && sig < m.pos {
+				c.Confidence = ConfidenceHigh
+			}
+		}
+		cs = append(cs, c)
+	}
+	return cs, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSalvage(t *testing.T) {
+	stream := testVorbis([]string{"LOOPSTART=10", "LOOPLENGTH=20"}, 2)
+	// The pages are damaged, but the comment fields are intact.
+	damaged := append([]byte{}, stream...)
+	for i := 0; ; {
+		j := bytes.Index(damaged[i:], []byte("OggS"))
+		if j < 0 {
+			break
+		}
+		copy(damaged[i+j:], "xxxx")
+		i += j + 4
+	}
+	// Without the signature, the fields are still Vorbis comment fields.
+	noSignature := bytes.Replace(damaged, []byte("\x03vorbis"), []byte("\x03xxxxxx"), -1)
+	field := func(s string) []byte {
+		return append(appendUint32(nil, uint32(len(s))), s...)
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+		want []Candidate
+	}{
+		{
+			name: "healthy stream",
+			data: stream,
+			want: []Candidate{
+				{Offset: int64(bytes.Index(stream, []byte("LOOPSTART"))), Key: "LOOPSTART", Value: 10, Confidence: ConfidenceHigh},
+				{Offset: int64(bytes.Index(stream, []byte("LOOPLENGTH"))), Key: "LOOPLENGTH", Value: 20, Confidence: ConfidenceHigh},
+			},
+		},
+		{
+			name: "damaged pages",
+			data: damaged,
+			want: []Candidate{
+				{Offset: int64(bytes.Index(stream, []byte("LOOPSTART"))), Key: "LOOPSTART", Value: 10, Confidence: ConfidenceHigh},
+				{Offset: int64(bytes.Index(stream, []byte("LOOPLENGTH"))), Key: "LOOPLENGTH", Value: 20, Confidence: ConfidenceHigh},
+			},
+		},
+		{
+			name: "no signature",
+			data: noSignature,
+			want: []Candidate{
+				{Offset: int64(bytes.Index(stream, []byte("LOOPSTART"))), Key: "LOOPSTART", Value: 10, Confidence: ConfidenceMedium},
+				{Offset: int64(bytes.Index(stream, []byte("LOOPLENGTH"))), Key: "LOOPLENGTH", Value: 20, Confidence: ConfidenceMedium},
+			},
+		},
+		{
+			// A field before the signature is not in the comment header.
+			name: "field before the signature",
+			data: append(append([]byte("xx"), field("LOOPEND=30")...), "\x03vorbis"...),
+			want: []Candidate{
+				{Offset: 6, Key: "LOOPEND", Value: 30, Confidence: ConfidenceMedium},
+			},
+		},
+		{
+			name: "text without lengths",
+			data: []byte("junk LOOPSTART=1 LOOPEND=2\x03vorbis"),
+			want: []Candidate{
+				{Offset: 5, Key: "LOOPSTART", Value: 1, Confidence: ConfidenceLow},
+				{Offset: 17, Key: "LOOPEND", Value: 2, Confidence: ConfidenceLow},
+			},
+		},
+		{
+			// A length that does not match the field is not a Vorbis comment field.
+			name: "wrong length",
+			data: append(appendUint32(nil, 100), "LOOPSTART=1"...),
+			want: []Candidate{
+				{Offset: 4, Key: "LOOPSTART", Value: 1, Confidence: ConfidenceLow},
+			},
+		},
+		{
+			name: "too large value",
+			data: []byte("LOOPSTART=99999999999999999999 LOOPLENGTH=3"),
+			want: []Candidate{
+				{Offset: 31, Key: "LOOPLENGTH", Value: 3, Confidence: ConfidenceLow},
+			},
+		},
+		{
+			name: "no tags",
+			data: []byte("LOOPSTART= LOOPLENGTH"),
+		},
+	}
+	for _, tc := range testCases {
+		got, err := Salvage(bytes.NewReader(tc.data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
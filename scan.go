@@ -0,0 +1,115 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"runtime"
+	"sync"
+)
+
+// ScanResult is the result for a file scanned by ScanDir.
+type ScanResult struct {
+	// Info is the audio parameters and the loop meta data of the file.
+	Info Info
+
+	// Format is the format of the file. Format is FormatUnknown when the format is not detected.
+	Format Format
+
+	// Err is the error reading the file, or nil when the file is read successfully.
+	Err error
+}
+
+// ScanDir walks the file tree fsys and reads the files whose base names match glob, e.g. "*.ogg", in the
+// same way as ReadAny, so the files can be of any supported format, e.g. "*.flac". The files are read in
+// parallel by as many workers as GOMAXPROCS.
+//
+// ScanDir returns the results keyed by the paths of the files in fsys. An error reading a file is
+// reported in its ScanResult, and does not stop the scanning. ScanDir returns an error only when glob
+// is malformed, when walking fsys fails, or when ctx is done.
+func ScanDir(ctx context.Context, fsys fs.FS, glob string) (map[string]ScanResult, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ok, _ := path.Match(glob, d.Name()); ok {
+			names = append(names, name)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var (
+		results = make(map[string]ScanResult, len(names))
+		m       sync.Mutex
+		wg      sync.WaitGroup
+		ch      = make(chan string)
+	)
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range ch {
+				info, format, err := readAnyFS(ctx, fsys, name)
+				m.Lock()
+				results[name] = ScanResult{
+					Info:   info,
+					Format: format,
+					Err:    err,
+				}
+				m.Unlock()
+			}
+		}()
+	}
+
+loop:
+	for _, name := range names {
+		select {
+		case ch <- name:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(ch)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func readAnyFS(ctx context.Context, fsys fs.FS, name string) (Info, Format, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return Info{}, FormatUnknown, err
+	}
+	defer f.Close()
+	return NewDecoder(f, nil).ReadAnyContext(ctx)
+}
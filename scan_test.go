@@ -0,0 +1,142 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.ogg":     {Data: testVorbis([]string{"LOOPSTART=1000", "LOOPLENGTH=500"}, 2)},
+		"b/c.flac":  {Data: testFLAC([]string{"LOOPSTART=2000", "LOOPLENGTH=600"})},
+		"b/d.wav":   {Data: testWAV([][2]uint32{{3000, 3699}})},
+		"b/e.txt":   {Data: []byte("text")},
+		"broken.au": {Data: []byte("broken")},
+	}
+	want := map[string]struct {
+		format Format
+		start  int64
+		length int64
+	}{
+		"a.ogg":    {FormatOgg, 1000, 500},
+		"b/c.flac": {FormatFLAC, 2000, 600},
+		"b/d.wav":  {FormatWAV, 3000, 700},
+	}
+	for _, glob := range []string{"*.ogg", "*.flac", "*.wav"} {
+		results, err := ScanDir(context.Background(), fsys, glob)
+		if err != nil {
+			t.Fatalf("ScanDir(%q): %v", glob, err)
+		}
+		if len(results) != 1 {
+			t.Errorf("ScanDir(%q): got %d results, want 1", glob, len(results))
+		}
+		for name, res := range results {
+			w, ok := want[name]
+			if !ok {
+				t.Errorf("ScanDir(%q): unexpected file %q", glob, name)
+				continue
+			}
+			if res.Err != nil {
+				t.Errorf("ScanDir(%q): %s: %v", glob, name, res.Err)
+				continue
+			}
+			if res.Format != w.format || res.Info.LoopStart != w.start || res.Info.LoopLength != w.length {
+				t.Errorf("ScanDir(%q): %s: got (%v, %d, %d), want (%v, %d, %d)", glob, name, res.Format, res.Info.LoopStart, res.Info.LoopLength, w.format, w.start, w.length)
+			}
+		}
+	}
+
+	results, err := ScanDir(context.Background(), fsys, "*.au")
+	if err != nil {
+		t.Fatalf("ScanDir(%q): %v", "*.au", err)
+	}
+	if res := results["broken.au"]; res.Err == nil || res.Format != FormatUnknown {
+		t.Errorf("ScanDir(%q): got (%v, %v), want an error and FormatUnknown", "*.au", res.Format, res.Err)
+	}
+}
+
+func TestScanDirCanceled(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.ogg": {Data: testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 1)},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ScanDir(ctx, fsys, "*.ogg"); err != context.Canceled {
+		t.Errorf("ScanDir with a canceled context: got %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestScanDirMany(t *testing.T) {
+	const n = 100
+	fsys := fstest.MapFS{}
+	for i := 0; i < n; i++ {
+		fsys[fmt.Sprintf("%d/%d.ogg", i%7, i)] = &fstest.MapFile{Data: testVorbis([]string{fmt.Sprintf("LOOPSTART=%d", i), "LOOPLENGTH=1"}, 1)}
+	}
+	results, err := ScanDir(context.Background(), fsys, "*.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != n {
+		t.Errorf("got %d results, want %d", len(results), n)
+	}
+	for name, res := range results {
+		var want int64
+		if _, err := fmt.Sscanf(path.Base(name), "%d.ogg", &want); err != nil {
+			t.Fatal(err)
+		}
+		if res.Err != nil || res.Info.LoopStart != want {
+			t.Errorf("%s: got (%d, %v), want (%d, nil)", name, res.Info.LoopStart, res.Err, want)
+		}
+	}
+}
+
+func TestScanDirMalformedGlob(t *testing.T) {
+	if _, err := ScanDir(context.Background(), fstest.MapFS{}, "["); err != path.ErrBadPattern {
+		t.Errorf("got %v, want %v", err, path.ErrBadPattern)
+	}
+}
+
+// cancelFS is an fs.FS that calls cancel when the n-th file matching *.ogg is opened.
+type cancelFS struct {
+	fs.FS
+	n      int32
+	cancel context.CancelFunc
+}
+
+func (f *cancelFS) Open(name string) (fs.File, error) {
+	if ok, _ := path.Match("*.ogg", path.Base(name)); ok && atomic.AddInt32(&f.n, -1) == 0 {
+		f.cancel()
+	}
+	return f.FS.Open(name)
+}
+
+func TestScanDirCanceledWhileReading(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 100; i++ {
+		fsys[fmt.Sprintf("%d.ogg", i)] = &fstest.MapFile{Data: testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 1)}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := ScanDir(ctx, &cancelFS{FS: fsys, n: 10, cancel: cancel}, "*.ogg"); err != context.Canceled {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
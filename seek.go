@@ -0,0 +1,41 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// OffsetForSample returns the byte offset of the page containing the given PCM sample, e.g.
+// LOOPSTART, in the first Vorbis, Opus or FLAC stream of r, in the same way as BuildSeekTable chooses
+// the stream.
+//
+// The page at the returned offset is the first page on which the packet including the sample
+// finishes. A decoder seeking to the sample should start decoding from the page before it.
+//
+// OffsetForSample reads the pages from the beginning for each call. To look up many samples, use
+// BuildSeekTable.
+func OffsetForSample(r io.ReaderAt, sample int64) (int64, error) {
+	if sample < 0 {
+		return 0, fmt.Errorf("oggloop: sample must be non-negative: %d", sample)
+	}
+	t, err := buildSeekTable(newPageReader(io.NewSectionReader(r, 0, math.MaxInt64), nil))
+	if err != nil {
+		return 0, err
+	}
+	return t.OffsetForSample(sample)
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// eofReaderAt is an io.ReaderAt that returns io.EOF with the last bytes, as io.ReaderAt allows.
+type eofReaderAt struct {
+	b []byte
+}
+
+func (r eofReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(r.b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b[off:])
+	if off+int64(n) == int64(len(r.b)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestOffsetForSample(t *testing.T) {
+	testCases := []struct {
+		name          string
+		data          []byte
+		samplePerPage int64
+		// firstAudioPage is the offset of the first audio page.
+		firstAudioPage int64
+	}{
+		{
+			name:           "Vorbis",
+			data:           testVorbis(nil, 3),
+			samplePerPage:  1000,
+			firstAudioPage: int64(len(testVorbis(nil, 0))),
+		},
+		{
+			name:           "Opus",
+			data:           testOpus(nil, 3),
+			samplePerPage:  960,
+			firstAudioPage: int64(len(testOpus(nil, 0))),
+		},
+	}
+	for _, tc := range testCases {
+		// Each audio page has the same size.
+		pageSize := (int64(len(tc.data)) - tc.firstAudioPage) / 3
+		for _, r := range []io.ReaderAt{bytes.NewReader(tc.data), eofReaderAt{tc.data}} {
+			for i := int64(0); i < 3; i++ {
+				sample := i*tc.samplePerPage + tc.samplePerPage/2
+				got, err := OffsetForSample(r, sample)
+				if err != nil {
+					t.Errorf("%s: OffsetForSample(%d): %v", tc.name, sample, err)
+					continue
+				}
+				if want := tc.firstAudioPage + i*pageSize; got != want {
+					t.Errorf("%s: OffsetForSample(%d): got %d, want %d", tc.name, sample, got, want)
+				}
+			}
+			if _, err := OffsetForSample(r, 3*tc.samplePerPage); err == nil {
+				t.Errorf("%s: OffsetForSample(%d): got no error", tc.name, 3*tc.samplePerPage)
+			}
+		}
+	}
+}
+
+func TestOffsetForSampleCorruptPage(t *testing.T) {
+	data := testVorbis(nil, 3)
+	// Break the capture pattern of the second audio page.
+	pageSize := (len(data) - len(testVorbis(nil, 0))) / 3
+	data[len(data)-2*pageSize] = 'X'
+	if _, err := OffsetForSample(bytes.NewReader(data), 2500); !errors.Is(err, ErrCorruptPage) {
+		t.Errorf("OffsetForSample: got %v, want %v", err, ErrCorruptPage)
+	}
+}
+
+func TestBuildSeekTable(t *testing.T) {
+	data := testVorbis(nil, 3)
+	first := int64(len(testVorbis(nil, 0)))
+	pageSize := (int64(len(data)) - first) / 3
+	prefix := []byte("junk!")
+	for _, tc := range []struct {
+		name string
+		src  io.Reader
+		// base is the offset of the stream in the offsets of the table.
+		base int64
+	}{
+		{name: "seeker", src: bytes.NewReader(append(prefix, data...)), base: int64(len(prefix))},
+		{name: "non-seeker", src: struct{ io.Reader }{bytes.NewReader(append(prefix, data...))}},
+	} {
+		// The offsets are absolute for a seeker, and relative to the current position for a non-seeker.
+		if _, err := io.ReadFull(tc.src, make([]byte, len(prefix))); err != nil {
+			t.Fatal(err)
+		}
+		st, err := BuildSeekTable(tc.src)
+		if err != nil {
+			t.Fatalf("%s: BuildSeekTable: %v", tc.name, err)
+		}
+		for _, c := range []struct {
+			sample int64
+			page   int64
+		}{
+			{0, 0},
+			{999, 0},
+			{1000, 1},
+			{2999, 2},
+		} {
+			got, err := st.OffsetForSample(c.sample)
+			if err != nil {
+				t.Errorf("%s: OffsetForSample(%d): %v", tc.name, c.sample, err)
+				continue
+			}
+			if want := tc.base + first + c.page*pageSize; got != want {
+				t.Errorf("%s: OffsetForSample(%d): got %d, want %d", tc.name, c.sample, got, want)
+			}
+		}
+		for _, sample := range []int64{-1, 3000} {
+			if _, err := st.OffsetForSample(sample); err == nil {
+				t.Errorf("%s: OffsetForSample(%d): got no error", tc.name, sample)
+			}
+		}
+	}
+}
+
+func TestBuildSeekTableStreams(t *testing.T) {
+	// Both logical streams have an audio page at the end, and the serial number 2 has twice as many samples.
+	data := testMultiplexed()
+	for _, tc := range []struct {
+		opts   *DecoderOptions
+		sample int64
+	}{
+		{nil, 999},
+		{&DecoderOptions{Serial: 2, HasSerial: true}, 1999},
+	} {
+		st, err := NewDecoder(bytes.NewReader(data), tc.opts).BuildSeekTable()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := st.OffsetForSample(tc.sample); err != nil {
+			t.Errorf("OffsetForSample(%d) with %+v: %v", tc.sample, tc.opts, err)
+		}
+		if _, err := st.OffsetForSample(tc.sample + 1); err == nil {
+			t.Errorf("OffsetForSample(%d) with %+v: got no error", tc.sample+1, tc.opts)
+		}
+	}
+
+	if _, err := BuildSeekTable(bytes.NewReader([]byte("not an Ogg stream"))); err != ErrNotOgg {
+		t.Errorf("BuildSeekTable: got %v, want %v", err, ErrNotOgg)
+	}
+	skeleton := testPage(headerTypeBOS|headerTypeEOS, 0, 1, 0, []byte("fishead\x00"))
+	if _, err := BuildSeekTable(bytes.NewReader(skeleton)); err != ErrNoVorbisStream {
+		t.Errorf("BuildSeekTable: got %v, want %v", err, ErrNoVorbisStream)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SeekTable maps PCM samples to the byte offsets of the pages of a logical stream.
+type SeekTable struct {
+	entries []seekTableEntry
+
+	// preSkip is the number of the samples discarded at the beginning of an Opus stream.
+	preSkip int64
+}
+
+type seekTableEntry struct {
+	// offset is the byte offset of the page.
+	offset int64
+
+	// granule is the granule position of the page.
+	granule int64
+}
+
+// BuildSeekTable reads the whole of the given src and records the byte offsets and the granule positions
+// of the pages of the first Vorbis, Opus or FLAC stream, in the same way as Read chooses the stream.
+//
+// The offsets are relative to the position of src when BuildSeekTable is called, unless src is an
+// io.Seeker, in which case the offsets are absolute positions in src.
+// If src is an io.Seeker, BuildSeekTable seeks past the page data instead of reading it.
+func BuildSeekTable(src io.Reader) (*SeekTable, error) {
+	return NewDecoder(src, nil).BuildSeekTable()
+}
+
+func buildSeekTable(pr *pageReader) (*SeekTable, error) {
+	var (
+		t           SeekTable
+		serial      uint32
+		c           codec
+		streamFound bool
+	)
+	for {
+		p, err := pr.next(func(p *page) bool {
+			// Only the beginning-of-stream pages are needed to choose the stream.
+			return streamFound
+		})
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !streamFound {
+			if p.headerType&headerTypeBOS == 0 {
+				break
+			}
+			if !pr.chooses(p) {
+				continue
+			}
+			c = detectCodec(p.body)
+			if c == codecOpus {
+				if len(p.body) < 19 {
+					return nil, errBrokenIdentHeader
+				}
+				t.preSkip = int64(binary.LittleEndian.Uint16(p.body[10:12]))
+			}
+			serial = p.serial
+			streamFound = true
+		}
+		if p.serial != serial {
+			continue
+		}
+
+		// A granule position -1 means that no packet finishes on the page.
+		if p.granule != -1 {
+			t.entries = append(t.entries, seekTableEntry{
+				offset:  pr.offset - p.size(),
+				granule: p.granule,
+			})
+		}
+		if p.headerType&headerTypeEOS != 0 {
+			break
+		}
+	}
+
+	if pr.pages == 0 {
+		return nil, ErrNotOgg
+	}
+	if !streamFound {
+		return nil, ErrNoVorbisStream
+	}
+	return &t, nil
+}
+
+// OffsetForSample returns the byte offset of the page containing the given PCM sample, e.g. LOOPSTART, in
+// the same way as the function OffsetForSample.
+//
+// For Opus, sample is a position after the pre-skip, like LOOPSTART and Info.TotalSamples.
+func (t *SeekTable) OffsetForSample(sample int64) (int64, error) {
+	if sample < 0 {
+		return 0, fmt.Errorf("oggloop: sample must be non-negative: %d", sample)
+	}
+	g := sample + t.preSkip
+	i := sort.Search(len(t.entries), func(i int) bool {
+		return g < t.entries[i].granule
+	})
+	if i == len(t.entries) {
+		return 0, fmt.Errorf("oggloop: sample %d is beyond the end of the stream", sample)
+	}
+	return t.entries[i].offset, nil
+}
@@ -0,0 +1,517 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LengthTag represents the tag from which the loop length comes.
+type LengthTag int
+
+const (
+	// LengthTagNone means that there is neither LOOPLENGTH nor LOOPEND.
+	LengthTagNone LengthTag = iota
+
+	// LengthTagLoopLength means that the loop length is the LOOPLENGTH value.
+	LengthTagLoopLength
+
+	// LengthTagLoopEnd means that the loop length is computed from the LOOPEND value, as LOOPEND minus
+	// LOOPSTART.
+	LengthTagLoopEnd
+)
+
+// Loop is a loop region.
+type Loop struct {
+	// Index is the index of the tags, e.g. 1 for LOOPSTART1 and LOOPLENGTH1.
+	// Index is -1 for the tags without an index.
+	Index int
+
+	// Start is the LOOPSTART value.
+	Start int64
+
+	// HasStart reports whether LOOPSTART exists. Start is 0 when it does not.
+	HasStart bool
+
+	// Length is the LOOPLENGTH value, or the length computed from the LOOPEND value.
+	Length int64
+
+	// LengthTag is the tag from which Length comes.
+	LengthTag LengthTag
+}
+
+// tagMatcher matches the loop tags in the fields of a comment header.
+type tagMatcher struct {
+	start  *tagPattern
+	length *tagPattern
+	end    *tagPattern
+
+	// indexedStart, indexedLength and indexedEnd match the tags with indices, e.g. LOOPSTART1.
+	indexedStart  *tagPattern
+	indexedLength *tagPattern
+	indexedEnd    *tagPattern
+
+	count *tagPattern
+	fade  *tagPattern
+}
+
+var defaultTagMatcher = compileTagMatcher(&DecoderOptions{})
+
+func newTagMatcher(opts *DecoderOptions) *tagMatcher {
+	if !opts.CaseInsensitiveKeys && !opts.AllowSpaces && len(opts.LoopStartKeys) == 0 && len(opts.LoopLengthKeys) == 0 && len(opts.LoopEndKeys) == 0 && !opts.TimeValues {
+		return defaultTagMatcher
+	}
+	return compileTagMatcher(opts)
+}
+
+func compileTagMatcher(opts *DecoderOptions) *tagMatcher {
+	loopValue := tagValueInteger
+	if opts.TimeValues {
+		loopValue = tagValueTime
+	}
+	return &tagMatcher{
+		start:         compileTag(opts, "LOOPSTART", opts.LoopStartKeys, false, loopValue),
+		length:        compileTag(opts, "LOOPLENGTH", opts.LoopLengthKeys, false, loopValue),
+		end:           compileTag(opts, "LOOPEND", opts.LoopEndKeys, false, loopValue),
+		indexedStart:  compileTag(opts, "LOOPSTART", opts.LoopStartKeys, true, loopValue),
+		indexedLength: compileTag(opts, "LOOPLENGTH", opts.LoopLengthKeys, true, loopValue),
+		indexedEnd:    compileTag(opts, "LOOPEND", opts.LoopEndKeys, true, loopValue),
+		count:         compileTag(opts, "LOOPCOUNT", nil, false, tagValueInteger),
+		fade:          compileTag(opts, "LOOPFADE", nil, false, tagValueDecimal),
+	}
+}
+
+// compileTag creates a pattern for the tag key or its alternatives.
+func compileTag(opts *DecoderOptions, key string, alternatives []string, indexed bool, value tagValue) *tagPattern {
+	return &tagPattern{
+		keys:            append([]string{key}, alternatives...),
+		caseInsensitive: opts.CaseInsensitiveKeys,
+		allowSpaces:     opts.AllowSpaces,
+		indexed:         indexed,
+		value:           value,
+	}
+}
+
+// tagValue represents the form of tag values.
+type tagValue int
+
+const (
+	// tagValueInteger is a non-negative integer, e.g. 1000.
+	tagValueInteger tagValue = iota
+
+	// tagValueDecimal is a non-negative decimal number, e.g. 1.5.
+	tagValueDecimal
+
+	// tagValueTime is a decimal number or a time in the form of [[hh:]mm:]ss[.fff], e.g. 01:23.5.
+	tagValueTime
+)
+
+// tagPattern matches a tag like LOOPSTART=1000 in a comment field, or anywhere in raw bytes for Salvage.
+//
+// tagPattern is a small hand-written matcher instead of a regular expression, so that the package does
+// not depend on the regexp package, which is relatively large for js/wasm and TinyGo builds.
+type tagPattern struct {
+	// keys is the tag keys to match. The earlier key is tried first.
+	keys []string
+
+	caseInsensitive bool
+
+	// allowSpaces reports whether spaces and tabs are allowed around "=".
+	allowSpaces bool
+
+	// indexed reports whether the key is followed by an index, e.g. LOOPSTART1.
+	indexed bool
+
+	// value is the form of the value.
+	value tagValue
+}
+
+// tagMatch is a tag matched by tagPattern.
+type tagMatch struct {
+	// pos and end are the byte offsets of the beginning and the end of the tag.
+	pos int
+	end int
+
+	// key is the key in tagPattern that matches the tag.
+	key string
+
+	// index is the index of the tag, or nil when the pattern is not indexed.
+	index []byte
+
+	value []byte
+}
+
+// matchField matches the whole of the given comment field. The key must be the whole field name, and the
+// value must be the whole field value.
+func (p *tagPattern) matchField(field string) (tagMatch, bool) {
+	data := []byte(field)
+	m, ok := p.matchAt(data, 0)
+	if !ok {
+		return tagMatch{}, false
+	}
+	end := m.end
+	if p.allowSpaces {
+		end += countSpaces(data[end:])
+	}
+	if end != len(data) {
+		return tagMatch{}, false
+	}
+	return m, true
+}
+
+// findAll returns all the tags in raw data that do not overlap.
+func (p *tagPattern) findAll(data []byte) []tagMatch {
+	var ms []tagMatch
+	for i := 0; i < len(data); {
+		m, ok := p.matchAt(data, i)
+		if !ok {
+			i++
+			continue
+		}
+		ms = append(ms, m)
+		i = m.end
+	}
+	return ms
+}
+
+// matchAt matches a tag starting at data[pos].
+func (p *tagPattern) matchAt(data []byte, pos int) (tagMatch, bool) {
+	for _, key := range p.keys {
+		if m, ok := p.matchKeyAt(data, pos, key); ok {
+			return m, true
+		}
+	}
+	return tagMatch{}, false
+}
+
+func (p *tagPattern) matchKeyAt(data []byte, pos int, key string) (tagMatch, bool) {
+	i := pos
+	if len(data)-i < len(key) {
+		return tagMatch{}, false
+	}
+	if k := data[i : i+len(key)]; p.caseInsensitive && !bytes.EqualFold(k, []byte(key)) || !p.caseInsensitive && string(k) != key {
+		return tagMatch{}, false
+	}
+	i += len(key)
+
+	m := tagMatch{
+		pos: pos,
+		key: key,
+	}
+	if p.indexed {
+		n := countDigits(data[i:])
+		if n == 0 {
+			return tagMatch{}, false
+		}
+		m.index = data[i : i+n]
+		i += n
+	}
+
+	if p.allowSpaces {
+		i += countSpaces(data[i:])
+	}
+	if i >= len(data) || data[i] != '=' {
+		return tagMatch{}, false
+	}
+	i++
+	if p.allowSpaces {
+		i += countSpaces(data[i:])
+	}
+
+	n := countDigits(data[i:])
+	if n == 0 {
+		return tagMatch{}, false
+	}
+	// Each of the hours and the minutes is followed by a colon and digits.
+	if p.value == tagValueTime {
+		for c := 0; c < 2 && i+n < len(data) && data[i+n] == ':'; c++ {
+			d := countDigits(data[i+n+1:])
+			if d == 0 {
+				break
+			}
+			n += 1 + d
+		}
+	}
+	// A fractional part needs at least one digit after the decimal point.
+	if p.value != tagValueInteger && i+n < len(data) && data[i+n] == '.' {
+		if f := countDigits(data[i+n+1:]); f > 0 {
+			n += 1 + f
+		}
+	}
+	m.value = data[i : i+n]
+	m.end = i + n
+	return m, true
+}
+
+func countDigits(data []byte) int {
+	for i, b := range data {
+		if b < '0' || '9' < b {
+			return i
+		}
+	}
+	return len(data)
+}
+
+func countSpaces(data []byte) int {
+	for i, b := range data {
+		if b != ' ' && b != '\t' {
+			return i
+		}
+	}
+	return len(data)
+}
+
+// parseLoopValue parses a value of a loop tag as a number of samples. A value with a decimal point or a
+// colon is a time, which is converted to samples at sampleRate.
+func parseLoopValue(key string, value []byte, sampleRate int) (int64, error) {
+	if bytes.ContainsAny(value, ".:") {
+		return parseTimeValue(key, value, sampleRate)
+	}
+	n, err := strconv.ParseInt(string(value), 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("oggloop: %s value %s overflows 64-bit integers", key, value)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("oggloop: invalid %s value %q: %w", key, value, err)
+	}
+	return n, nil
+}
+
+// parseTimeValue parses a time in the form of [[hh:]mm:]ss[.fff] and converts it to samples at sampleRate.
+func parseTimeValue(key string, value []byte, sampleRate int) (int64, error) {
+	if sampleRate <= 0 {
+		return 0, fmt.Errorf("oggloop: %s value %s is a time but the sample rate of the stream is unknown", key, value)
+	}
+
+	v := string(value)
+	var frac string
+	if i := strings.IndexByte(v, '.'); i >= 0 {
+		v, frac = v[:i], v[i+1:]
+	}
+
+	// Accumulate the integer part in seconds.
+	var seconds int64
+	for i, f := range strings.Split(v, ":") {
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("oggloop: invalid %s value %q: %w", key, value, err)
+		}
+		// The minutes and the seconds after a colon must be less than 60.
+		if i > 0 && n >= 60 {
+			return 0, fmt.Errorf("oggloop: invalid %s value %q", key, value)
+		}
+		if err != nil || seconds > (math.MaxInt64-n)/60 {
+			return 0, fmt.Errorf("oggloop: %s value %s overflows 64-bit integers", key, value)
+		}
+		// Ignore the multiplication for the first field, which can be as large as the hours.
+		if i > 0 {
+			seconds *= 60
+		}
+		seconds += n
+	}
+
+	// Digits after nanoseconds do not affect the result.
+	if len(frac) > 9 {
+		frac = frac[:9]
+	}
+	var nanos int64
+	if frac != "" {
+		n, err := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("oggloop: invalid %s value %q: %w", key, value, err)
+		}
+		nanos = n
+	}
+
+	rate := int64(sampleRate)
+	if seconds > (math.MaxInt64-rate)/rate {
+		return 0, fmt.Errorf("oggloop: %s value %s overflows 64-bit integers", key, value)
+	}
+	return seconds*rate + (nanos*rate+5e8)/1e9, nil
+}
+
+// newLoop creates a Loop from the values of the tags. A nil value means that the tag does not exist.
+//
+// Some tools write LOOPEND, the sample at which the loop ends, instead of LOOPLENGTH. When both exist,
+// LOOPLENGTH takes precedence.
+func newLoop(index int, start, length, end []byte, sampleRate int) (Loop, error) {
+	var suffix string
+	if index >= 0 {
+		suffix = strconv.Itoa(index)
+	}
+
+	l := Loop{
+		Index: index,
+	}
+	if start != nil {
+		v, err := parseLoopValue("LOOPSTART"+suffix, start, sampleRate)
+		if err != nil {
+			return Loop{}, err
+		}
+		l.Start = v
+		l.HasStart = true
+	}
+	if length != nil {
+		v, err := parseLoopValue("LOOPLENGTH"+suffix, length, sampleRate)
+		if err != nil {
+			return Loop{}, err
+		}
+		// The end of the loop must be representable as a sample position.
+		if v > math.MaxInt64-l.Start {
+			return Loop{}, fmt.Errorf("oggloop: LOOPSTART%s %d plus LOOPLENGTH%s %d overflows 64-bit integers", suffix, l.Start, suffix, v)
+		}
+		l.Length = v
+		l.LengthTag = LengthTagLoopLength
+	} else if end != nil {
+		v, err := parseLoopValue("LOOPEND"+suffix, end, sampleRate)
+		if err != nil {
+			return Loop{}, err
+		}
+		if v < l.Start {
+			return Loop{}, fmt.Errorf("oggloop: LOOPEND%s %d is before LOOPSTART%s %d", suffix, v, suffix, l.Start)
+		}
+		l.Length = v - l.Start
+		l.LengthTag = LengthTagLoopEnd
+	}
+	return l, nil
+}
+
+// loopTags returns the loop regions in the given comment fields. sampleRate is the sample rate of
+// the stream to convert the values in seconds, or 0 if it is unknown.
+//
+// A field is a tag only when its whole name is a key and its whole value is a value, so that a key in the
+// value of another field, e.g. COMMENT=set LOOPSTART=5 later, is not a tag.
+//
+// The tags without indices come first. The primary loop, the first one, takes the first value of each
+// tag. When such tags are repeated, each other LOOPSTART and the following LOOPLENGTH or LOOPEND make a
+// loop. The tags with indices follow in the order of the indices.
+//
+// Only a broken primary loop of the tags without indices is an error. The other loops that are broken,
+// e.g. with an index too large or LOOPEND before LOOPSTART, are skipped.
+func (t *tagMatcher) loopTags(fields []string, sampleRate int) ([]Loop, error) {
+	var loops []Loop
+
+	// A tag belongs to the current loop unless the loop already has the same kind of tag.
+	var (
+		first   [3][]byte
+		current *[3][]byte
+		values  [][3][]byte
+	)
+	for _, f := range fields {
+		for kind, p := range []*tagPattern{t.start, t.length, t.end} {
+			m, ok := p.matchField(f)
+			if !ok {
+				continue
+			}
+			if first[kind] == nil {
+				first[kind] = m.value
+			}
+			if current == nil || current[kind] != nil {
+				values = append(values, [3][]byte{})
+				current = &values[len(values)-1]
+			}
+			current[kind] = m.value
+			break
+		}
+	}
+	// The first one wins for the primary loop when the same tag is repeated, as RPG Maker does.
+	if len(values) > 0 {
+		values[0] = first
+	}
+	for i, v := range values {
+		l, err := newLoop(-1, v[0], v[1], v[2], sampleRate)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+		loops = append(loops, l)
+	}
+
+	// indexed maps an index to the values of LOOPSTART, LOOPLENGTH and LOOPEND with the index.
+	indexed := map[int]*[3][]byte{}
+	for _, f := range fields {
+		for i, p := range []*tagPattern{t.indexedStart, t.indexedLength, t.indexedEnd} {
+			m, ok := p.matchField(f)
+			if !ok {
+				continue
+			}
+			index, err := strconv.Atoi(string(m.index))
+			if err != nil {
+				continue
+			}
+			v, ok := indexed[index]
+			if !ok {
+				v = &[3][]byte{}
+				indexed[index] = v
+			}
+			// The first one wins when the same tag is repeated.
+			if v[i] == nil {
+				v[i] = m.value
+			}
+			break
+		}
+	}
+	indices := make([]int, 0, len(indexed))
+	for index := range indexed {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	for _, index := range indices {
+		v := indexed[index]
+		l, err := newLoop(index, v[0], v[1], v[2], sampleRate)
+		if err != nil {
+			continue
+		}
+		loops = append(loops, l)
+	}
+
+	return loops, nil
+}
+
+// auxTags returns the values of the auxiliary tags LOOPCOUNT and LOOPFADE in the given comment fields.
+// The first field of each tag is used. The values are 0 when the tags do not exist or are malformed, as
+// the tags are optional hints.
+//
+// LOOPCOUNT is the number of times to play the loop region, and LOOPFADE is the length of the fade-out
+// after the loops, in seconds.
+func (t *tagMatcher) auxTags(fields []string) (count int, fade time.Duration) {
+	var countFound, fadeFound bool
+	for _, f := range fields {
+		if m, ok := t.count.matchField(f); ok && !countFound {
+			countFound = true
+			if v, err := strconv.Atoi(string(m.value)); err == nil && v >= 0 {
+				count = v
+			}
+		}
+		if m, ok := t.fade.matchField(f); ok && !fadeFound {
+			fadeFound = true
+			v, err := strconv.ParseFloat(string(m.value), 64)
+			// NaN fails the comparisons.
+			if err == nil && v >= 0 && v*float64(time.Second) <= math.MaxInt64 {
+				fade = time.Duration(v * float64(time.Second))
+			}
+		}
+	}
+	return count, fade
+}
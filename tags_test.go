@@ -0,0 +1,328 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeValue(t *testing.T) {
+	testCases := []struct {
+		value      string
+		sampleRate int
+		want       int64
+		err        string
+	}{
+		{value: "1", sampleRate: 44100, want: 44100},
+		{value: "1.5", sampleRate: 44100, want: 66150},
+		{value: "01:30", sampleRate: 44100, want: 90 * 44100},
+		{value: "1:00:00", sampleRate: 44100, want: 3600 * 44100},
+		{value: "01:02:03.25", sampleRate: 48000, want: 3723*48000 + 12000},
+
+		// The first field can be as large as the hours.
+		{value: "100:00", sampleRate: 44100, want: 6000 * 44100},
+		{value: "100", sampleRate: 44100, want: 100 * 44100},
+
+		// The fields after a colon must be less than 60.
+		{value: "1:60", sampleRate: 44100, err: "invalid"},
+		{value: "1:60:00", sampleRate: 44100, err: "invalid"},
+		{value: "1:00:60", sampleRate: 44100, err: "invalid"},
+		{value: "1:59:59", sampleRate: 1, want: 7199},
+
+		// The fraction is rounded to the nearest sample.
+		{value: "0.1", sampleRate: 3, want: 0},
+		{value: "0.2", sampleRate: 3, want: 1},
+		{value: "0.5", sampleRate: 3, want: 2},
+		{value: "0.00001", sampleRate: 44100, want: 0},
+		{value: "0.00002", sampleRate: 44100, want: 1},
+
+		// Digits after nanoseconds are ignored.
+		{value: "1.123456789", sampleRate: 48000, want: 48000 + 5926},
+		{value: "1.1234567899999", sampleRate: 48000, want: 48000 + 5926},
+		{value: "0.0000000009", sampleRate: 1000000000, want: 0},
+		{value: "0.000000001", sampleRate: 1000000000, want: 1},
+
+		// Overflow.
+		{value: "9223372036854775806", sampleRate: 1, want: 9223372036854775806},
+		{value: "9223372036854775807", sampleRate: 1, err: "overflows"},
+		{value: "9223372036854775807", sampleRate: 44100, err: "overflows"},
+		{value: "99999999999999999999", sampleRate: 44100, err: "overflows"},
+		{value: "153722867280912930:00", sampleRate: 1, want: 9223372036854775800},
+		{value: "153722867280912931:00", sampleRate: 1, err: "overflows"},
+		{value: "1:99999999999999999999", sampleRate: 1, err: "invalid"},
+
+		{value: "1:x", sampleRate: 44100, err: "invalid"},
+		{value: "1", sampleRate: 0, err: "sample rate of the stream is unknown"},
+	}
+	for _, tc := range testCases {
+		got, err := parseTimeValue("LOOPSTART", []byte(tc.value), tc.sampleRate)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("parseTimeValue(%q, %d): got error %v, want an error containing %q", tc.value, tc.sampleRate, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTimeValue(%q, %d): %v", tc.value, tc.sampleRate, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseTimeValue(%q, %d): got %d, want %d", tc.value, tc.sampleRate, got, tc.want)
+		}
+	}
+}
+
+func TestReadLoopEnd(t *testing.T) {
+	testCases := []struct {
+		comments []string
+		length   int64
+		tag      LengthTag
+		err      string
+	}{
+		{comments: []string{"LOOPSTART=10", "LOOPEND=30"}, length: 20, tag: LengthTagLoopEnd},
+		{comments: []string{"LOOPSTART=10", "LOOPEND=10"}, length: 0, tag: LengthTagLoopEnd},
+		// LOOPLENGTH takes precedence over LOOPEND.
+		{comments: []string{"LOOPSTART=10", "LOOPEND=30", "LOOPLENGTH=5"}, length: 5, tag: LengthTagLoopLength},
+		{comments: []string{"LOOPSTART=10"}, length: 0, tag: LengthTagNone},
+		{comments: []string{"LOOPSTART=10", "LOOPEND=9"}, err: "before LOOPSTART"},
+	}
+	for _, tc := range testCases {
+		info, err := ReadInfo(bytes.NewReader(testVorbis(tc.comments, 1)))
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("ReadInfo(%q): got error %v, want an error containing %q", tc.comments, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ReadInfo(%q): %v", tc.comments, err)
+			continue
+		}
+		if info.LoopStart != 10 || info.LoopLength != tc.length || info.LengthTag != tc.tag {
+			t.Errorf("ReadInfo(%q): got (%d, %d, %v), want (10, %d, %v)", tc.comments, info.LoopStart, info.LoopLength, info.LengthTag, tc.length, tc.tag)
+		}
+	}
+}
+
+func TestReadOverflow(t *testing.T) {
+	testCases := []struct {
+		comments []string
+		err      string
+	}{
+		{comments: []string{"LOOPSTART=9223372036854775808", "LOOPLENGTH=1"}, err: "LOOPSTART value 9223372036854775808 overflows"},
+		{comments: []string{"LOOPSTART=1", "LOOPLENGTH=9223372036854775808"}, err: "LOOPLENGTH value 9223372036854775808 overflows"},
+		{comments: []string{"LOOPSTART=1", "LOOPEND=9223372036854775808"}, err: "LOOPEND value 9223372036854775808 overflows"},
+		{comments: []string{"LOOPSTART=1", "LOOPLENGTH=9223372036854775807"}, err: "LOOPSTART 1 plus LOOPLENGTH 9223372036854775807 overflows"},
+	}
+	for _, tc := range testCases {
+		_, _, err := Read(bytes.NewReader(testVorbis(tc.comments, 1)))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Read(%q): got error %v, want an error containing %q", tc.comments, err, tc.err)
+		}
+	}
+
+	// The largest values that fit in 64-bit integers.
+	start, length, err := Read(bytes.NewReader(testVorbis([]string{"LOOPSTART=0", "LOOPLENGTH=9223372036854775807"}, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != 0 || length != 9223372036854775807 {
+		t.Errorf("got (%d, %d), want (0, 9223372036854775807)", start, length)
+	}
+	start, length, err = Read(bytes.NewReader(testVorbis([]string{"LOOPSTART=9223372036854775807", "LOOPEND=9223372036854775807"}, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != 9223372036854775807 || length != 0 {
+		t.Errorf("got (%d, %d), want (9223372036854775807, 0)", start, length)
+	}
+}
+
+func TestReadLoops(t *testing.T) {
+	testCases := []struct {
+		comments []string
+		want     []Loop
+	}{
+		{
+			// The loops without indices come first, and the loops with indices follow in the order of the
+			// indices.
+			comments: []string{"LOOPSTART2=20", "LOOPLENGTH2=2", "LOOPSTART=1", "LOOPLENGTH=3", "LOOPSTART0=5", "LOOPEND0=9", "LOOPSTART=7", "LOOPLENGTH=1", "LOOPSTART1=10"},
+			want: []Loop{
+				{Index: -1, Start: 1, HasStart: true, Length: 3, LengthTag: LengthTagLoopLength},
+				{Index: -1, Start: 7, HasStart: true, Length: 1, LengthTag: LengthTagLoopLength},
+				{Index: 0, Start: 5, HasStart: true, Length: 4, LengthTag: LengthTagLoopEnd},
+				{Index: 1, Start: 10, HasStart: true},
+				{Index: 2, Start: 20, HasStart: true, Length: 2, LengthTag: LengthTagLoopLength},
+			},
+		},
+		{
+			// The first loop with an index is the primary loop when there are no tags without indices.
+			comments: []string{"LOOPSTART1=10", "LOOPLENGTH1=2"},
+			want: []Loop{
+				{Index: 1, Start: 10, HasStart: true, Length: 2, LengthTag: LengthTagLoopLength},
+			},
+		},
+		{
+			// Leading zeros of an index are ignored.
+			comments: []string{"LOOPSTART01=10", "LOOPLENGTH1=2"},
+			want: []Loop{
+				{Index: 1, Start: 10, HasStart: true, Length: 2, LengthTag: LengthTagLoopLength},
+			},
+		},
+		{
+			comments: []string{"LOOPLENGTH=5"},
+			want: []Loop{
+				{Index: -1, Length: 5, LengthTag: LengthTagLoopLength},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		for _, data := range [][]byte{testVorbis(tc.comments, 1), testFLAC(tc.comments)} {
+			info, _, err := ReadAny(bytes.NewReader(data))
+			if err != nil {
+				t.Errorf("ReadAny(%q): %v", tc.comments, err)
+				continue
+			}
+			if !reflect.DeepEqual(info.Loops, tc.want) {
+				t.Errorf("ReadAny(%q): got %+v, want %+v", tc.comments, info.Loops, tc.want)
+			}
+			if p := tc.want[0]; info.LoopStart != p.Start || info.LoopLength != p.Length || info.HasLoopStart != p.HasStart {
+				t.Errorf("ReadAny(%q): got the primary loop (%d, %d, %t), want (%d, %d, %t)", tc.comments, info.LoopStart, info.LoopLength, info.HasLoopStart, p.Start, p.Length, p.HasStart)
+			}
+		}
+	}
+}
+
+func TestReadAuxTags(t *testing.T) {
+	testCases := []struct {
+		comments []string
+		count    int
+		fade     time.Duration
+	}{
+		{comments: []string{"LOOPCOUNT=3", "LOOPFADE=2.5"}, count: 3, fade: 2500 * time.Millisecond},
+		{comments: []string{"LOOPFADE=10"}, fade: 10 * time.Second},
+		{comments: []string{"LOOPCOUNT=0", "LOOPFADE=0"}},
+		// The first field of each tag is used.
+		{comments: []string{"LOOPCOUNT=2", "LOOPCOUNT=5", "LOOPFADE=1", "LOOPFADE=4"}, count: 2, fade: time.Second},
+		// A malformed first tag is not replaced by a later one.
+		{comments: []string{"LOOPCOUNT=99999999999999999999", "LOOPCOUNT=5"}},
+		// A field whose value is not a number is not a tag.
+		{comments: []string{"LOOPCOUNT=x", "LOOPCOUNT=5"}, count: 5},
+		{comments: nil},
+	}
+	for _, tc := range testCases {
+		comments := append([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, tc.comments...)
+		for _, data := range [][]byte{testVorbis(comments, 1), testFLAC(comments)} {
+			info, _, err := ReadAny(bytes.NewReader(data))
+			if err != nil {
+				t.Errorf("ReadAny(%q): %v", comments, err)
+				continue
+			}
+			if info.LoopCount != tc.count || info.LoopFade != tc.fade {
+				t.Errorf("ReadAny(%q): got LoopCount %d and LoopFade %v, want %d and %v", comments, info.LoopCount, info.LoopFade, tc.count, tc.fade)
+			}
+		}
+	}
+}
+
+func TestReadTagOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		comments []string
+		opts     DecoderOptions
+		start    int64
+		length   int64
+		err      error
+	}{
+		{
+			name:     "lower-case keys by default",
+			comments: []string{"loopstart=1", "looplength=2"},
+			err:      ErrNoLoopTags,
+		},
+		{
+			name:     "spaces by default",
+			comments: []string{"LOOPSTART = 1", "LOOPLENGTH = 2"},
+			err:      ErrNoLoopTags,
+		},
+		{
+			name:     "case-insensitive keys",
+			comments: []string{"LoopStart=1", "looplength=2"},
+			opts:     DecoderOptions{CaseInsensitiveKeys: true},
+			start:    1,
+			length:   2,
+		},
+		{
+			name:     "case-insensitive keys without spaces",
+			comments: []string{"loopstart = 1", "LOOPLENGTH=2"},
+			opts:     DecoderOptions{CaseInsensitiveKeys: true},
+			length:   2,
+		},
+		{
+			name:     "spaces and tabs",
+			comments: []string{"LOOPSTART \t= 1", "LOOPLENGTH=\t2 "},
+			opts:     DecoderOptions{AllowSpaces: true},
+			start:    1,
+			length:   2,
+		},
+		{
+			name:     "alternative keys",
+			comments: []string{"LOOP_START=5", "LOOP_LENGTH=6"},
+			opts:     DecoderOptions{LoopStartKeys: []string{"LOOP_START"}, LoopLengthKeys: []string{"LOOP_LENGTH"}},
+			start:    5,
+			length:   6,
+		},
+		{
+			name:     "alternative end key",
+			comments: []string{"LOOPSTART=5", "LOOP_END=9"},
+			opts:     DecoderOptions{LoopEndKeys: []string{"LOOP_END"}},
+			start:    5,
+			length:   4,
+		},
+		{
+			name:     "original keys with alternative keys",
+			comments: []string{"LOOPSTART=7", "LOOPLENGTH=8"},
+			opts:     DecoderOptions{LoopStartKeys: []string{"LOOP_START"}, LoopLengthKeys: []string{"LOOP_LENGTH"}},
+			start:    7,
+			length:   8,
+		},
+		{
+			name:     "case-insensitive alternative keys",
+			comments: []string{"loop_start = 5", "LOOPLENGTH=6"},
+			opts:     DecoderOptions{CaseInsensitiveKeys: true, AllowSpaces: true, LoopStartKeys: []string{"LOOP_START"}},
+			start:    5,
+			length:   6,
+		},
+		{
+			name:     "alternative keys without options",
+			comments: []string{"LOOP_START=5", "LOOPLENGTH=6"},
+			length:   6,
+		},
+	}
+	for _, tc := range testCases {
+		opts := tc.opts
+		start, length, err := NewDecoder(bytes.NewReader(testVorbis(tc.comments, 1)), &opts).Read()
+		if err != tc.err {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+			continue
+		}
+		if start != tc.start || length != tc.length {
+			t.Errorf("%s: got (%d, %d), want (%d, %d)", tc.name, start, length, tc.start, tc.length)
+		}
+	}
+}
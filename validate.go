@@ -0,0 +1,96 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"fmt"
+)
+
+// WarningKind represents a kind of problems of loop points.
+type WarningKind int
+
+const (
+	// WarningLoopStartBeyondEnd means that LOOPSTART is at or after the end of the stream.
+	WarningLoopStartBeyondEnd WarningKind = iota + 1
+
+	// WarningLoopEndBeyondEnd means that the end of the loop is after the end of the stream.
+	WarningLoopEndBeyondEnd
+
+	// WarningZeroLengthLoop means that the loop length is 0.
+	WarningZeroLengthLoop
+
+	// WarningLoopEndNearEnd means that the end of the loop is slightly before the end of the stream, which
+	// is likely an off-by-some error of an exporter.
+	WarningLoopEndNearEnd
+)
+
+// Warning is a problem of loop points found by Info.Validate.
+type Warning struct {
+	// Kind is the kind of the problem.
+	Kind WarningKind
+
+	// Message describes the problem.
+	Message string
+}
+
+// String implements fmt.Stringer.
+func (w Warning) String() string {
+	return w.Message
+}
+
+// Validate checks the loop points against the stream, and returns the problems found.
+// Validate returns nil when the info has neither LOOPSTART nor a loop length, i.e. HasLoopStart and
+// HasLoopLength are false, or has no problems.
+//
+// A loop without a length extends to the end of the stream in the same way as NewLoopReader and
+// InfiniteLoopBytes, so only LOOPSTART is checked for such a loop.
+// The checks against the end of the stream are skipped when TotalSamples is 0, i.e. unknown.
+func (i Info) Validate() []Warning {
+	if !i.HasLoopStart && !i.HasLoopLength {
+		return nil
+	}
+
+	var warnings []Warning
+	add := func(kind WarningKind, format string, args ...any) {
+		warnings = append(warnings, Warning{
+			Kind:    kind,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if i.HasLoopLength && i.LoopLength == 0 {
+		add(WarningZeroLengthLoop, "the loop length is 0")
+	}
+	if i.TotalSamples == 0 {
+		return warnings
+	}
+
+	if i.LoopStart >= i.TotalSamples {
+		add(WarningLoopStartBeyondEnd, "LOOPSTART %d is at or after the end of the stream %d", i.LoopStart, i.TotalSamples)
+		return warnings
+	}
+	if !i.HasLoopLength {
+		return warnings
+	}
+	end := i.LoopStart + i.LoopLength
+	switch {
+	case end > i.TotalSamples:
+		add(WarningLoopEndBeyondEnd, "the end of the loop %d is after the end of the stream %d", end, i.TotalSamples)
+	case i.LoopLength > 0 && end < i.TotalSamples && i.TotalSamples-end <= int64(i.SampleRate)/100:
+		// A gap shorter than 10 milliseconds is too short to be intended.
+		add(WarningLoopEndNearEnd, "the end of the loop %d is %d samples before the end of the stream %d", end, i.TotalSamples-end, i.TotalSamples)
+	}
+	return warnings
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	// The test streams have 3000 samples at 44.1 kHz.
+	testCases := []struct {
+		comments []string
+		kinds    []WarningKind
+	}{
+		{
+			comments: nil,
+			kinds:    nil,
+		},
+		{
+			comments: []string{"LOOPSTART=1000", "LOOPLENGTH=1000"},
+			kinds:    nil,
+		},
+		// A loop without a length extends to the end of the stream.
+		{
+			comments: []string{"LOOPSTART=1000"},
+			kinds:    nil,
+		},
+		{
+			comments: []string{"LOOPSTART=0"},
+			kinds:    nil,
+		},
+		{
+			comments: []string{"LOOPSTART=3000"},
+			kinds:    []WarningKind{WarningLoopStartBeyondEnd},
+		},
+		{
+			comments: []string{"LOOPSTART=1000", "LOOPLENGTH=0"},
+			kinds:    []WarningKind{WarningZeroLengthLoop},
+		},
+		{
+			comments: []string{"LOOPSTART=1000", "LOOPLENGTH=2001"},
+			kinds:    []WarningKind{WarningLoopEndBeyondEnd},
+		},
+		{
+			comments: []string{"LOOPSTART=1000", "LOOPLENGTH=1990"},
+			kinds:    []WarningKind{WarningLoopEndNearEnd},
+		},
+	}
+	for _, tc := range testCases {
+		info, err := ReadInfo(bytes.NewReader(testVorbis(tc.comments, 3)))
+		if err != nil {
+			t.Errorf("ReadInfo(%q): %v", tc.comments, err)
+			continue
+		}
+		var kinds []WarningKind
+		for _, w := range info.Validate() {
+			kinds = append(kinds, w.Kind)
+		}
+		if !reflect.DeepEqual(kinds, tc.kinds) {
+			t.Errorf("Validate for %q: got %v, want %v", tc.comments, kinds, tc.kinds)
+		}
+	}
+}
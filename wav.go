@@ -0,0 +1,150 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotWAV is returned when the stream is not a RIFF/WAVE stream.
+var ErrNotWAV = errors.New("oggloop: not a WAV stream")
+
+// ReadWAV reads the given src as a RIFF/WAVE stream and returns the audio parameters and the loops of the
+// smpl chunk as Info. The first loop is the primary one, and the Index of each Loop is its position in the
+// smpl chunk.
+//
+// The end of a loop in a smpl chunk is inclusive, so LoopLength is the end minus the start plus 1, and
+// LengthTag is LengthTagLoopEnd. When the stream does not have a smpl chunk with a loop, LoopStart and
+// LoopLength are 0, LengthTag is LengthTagNone, and HasLoopStart and HasLoopLength are false.
+//
+// ReadWAV returns ErrNotWAV when src is not a RIFF/WAVE stream. If src is an io.Seeker, ReadWAV seeks
+// past the chunks it does not need instead of reading them.
+func ReadWAV(src io.Reader) (Info, error) {
+	return NewDecoder(src, nil).ReadWAV()
+}
+
+func readWAV(pr *pageReader) (Info, error) {
+	h, err := pr.readBytes(12)
+	if err == io.ErrUnexpectedEOF {
+		return Info{}, ErrNotWAV
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	if string(h[:4]) != "RIFF" || string(h[8:12]) != "WAVE" {
+		return Info{}, ErrNotWAV
+	}
+	// The RIFF size counts the bytes after the size field itself.
+	end := pr.offset - 4 + int64(binary.LittleEndian.Uint32(h[4:8]))
+
+	var (
+		info       Info
+		fmtFound   bool
+		blockAlign int64
+		dataSize   int64
+		dataFound  bool
+		smplFound  bool
+	)
+	for pr.offset < end && (!fmtFound || !dataFound || !smplFound) {
+		ch, err := pr.readBytes(8)
+		// Some encoders write a wrong RIFF size. Treat the end of the stream at a chunk as the end.
+		if err == io.ErrUnexpectedEOF && fmtFound {
+			break
+		}
+		if err != nil {
+			return Info{}, err
+		}
+		id := string(ch[:4])
+		size := int64(binary.LittleEndian.Uint32(ch[4:8]))
+		// A chunk is padded to an even size.
+		padded := size + size%2
+
+		var read int64
+		switch id {
+		case "fmt ":
+			// https://learn.microsoft.com/en-us/windows/win32/api/mmeapi/ns-mmeapi-waveformatex
+			if size < 16 {
+				return Info{}, fmt.Errorf("%w: broken fmt chunk", ErrCorruptPage)
+			}
+			b, err := pr.readBytes(16)
+			if err != nil {
+				return Info{}, err
+			}
+			read = 16
+			info.Channels = int(binary.LittleEndian.Uint16(b[2:4]))
+			info.SampleRate = int(binary.LittleEndian.Uint32(b[4:8]))
+			blockAlign = int64(binary.LittleEndian.Uint16(b[12:14]))
+			fmtFound = true
+		case "data":
+			dataSize = size
+			dataFound = true
+		case "smpl":
+			// The smpl chunk has a 36-byte header followed by 24-byte loops.
+			// https://www.recordingblogs.com/wiki/sample-chunk-of-a-wave-file
+			if size < 36 {
+				return Info{}, fmt.Errorf("%w: broken smpl chunk", ErrCorruptPage)
+			}
+			b, err := pr.readBytes(36)
+			if err != nil {
+				return Info{}, err
+			}
+			read = 36
+			n := int64(binary.LittleEndian.Uint32(b[28:32]))
+			if m := (size - 36) / 24; n > m {
+				n = m
+			}
+			var loops []Loop
+			for i := 0; i < int(n); i++ {
+				b, err := pr.readBytes(24)
+				if err != nil {
+					return Info{}, err
+				}
+				read += 24
+				start := int64(binary.LittleEndian.Uint32(b[8:12]))
+				loopEnd := int64(binary.LittleEndian.Uint32(b[12:16]))
+				if loopEnd < start {
+					// Only a broken first loop is an error, in the same way as the loop tags.
+					if i == 0 {
+						return Info{}, fmt.Errorf("oggloop: smpl loop end %d is before the start %d", loopEnd, start)
+					}
+					continue
+				}
+				loops = append(loops, Loop{
+					Index:     i,
+					Start:     start,
+					HasStart:  true,
+					Length:    loopEnd - start + 1,
+					LengthTag: LengthTagLoopEnd,
+				})
+			}
+			info.setLoops(loops)
+			smplFound = true
+		}
+		if err := pr.skipBytes(padded - read); err != nil {
+			return Info{}, err
+		}
+	}
+
+	if !fmtFound {
+		return Info{}, fmt.Errorf("%w: no fmt chunk", ErrCorruptPage)
+	}
+	if blockAlign > 0 {
+		info.TotalSamples = dataSize / blockAlign
+	}
+	return info, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// testWAV returns a 16-bit stereo RIFF/WAVE stream of 1000 samples with a smpl chunk of the given loops.
+// Each loop is a pair of the start and the inclusive end.
+func testWAV(loops [][2]uint32) []byte {
+	chunk := func(id string, data []byte) []byte {
+		b := append([]byte(id), appendUint32(nil, uint32(len(data)))...)
+		return append(b, data...)
+	}
+
+	var fmtData []byte
+	fmtData = append(fmtData, 1, 0, 2, 0)
+	fmtData = appendUint32(fmtData, 44100)
+	fmtData = appendUint32(fmtData, 44100*4)
+	fmtData = append(fmtData, 4, 0, 16, 0)
+
+	smpl := make([]byte, 28)
+	smpl = appendUint32(smpl, uint32(len(loops)))
+	smpl = appendUint32(smpl, 0)
+	for i, l := range loops {
+		smpl = appendUint32(smpl, uint32(i))
+		smpl = appendUint32(smpl, 0)
+		smpl = appendUint32(smpl, l[0])
+		smpl = appendUint32(smpl, l[1])
+		smpl = appendUint32(smpl, 0)
+		smpl = appendUint32(smpl, 0)
+	}
+
+	body := []byte("WAVE")
+	body = append(body, chunk("fmt ", fmtData)...)
+	body = append(body, chunk("smpl", smpl)...)
+	body = append(body, chunk("data", make([]byte, 4000))...)
+	return chunk("RIFF", body)
+}
+
+func TestReadWAVLoops(t *testing.T) {
+	info, err := ReadWAV(bytes.NewReader(testWAV([][2]uint32{{100, 899}, {200, 299}})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Loop{
+		{Index: 0, Start: 100, HasStart: true, Length: 800, LengthTag: LengthTagLoopEnd},
+		{Index: 1, Start: 200, HasStart: true, Length: 100, LengthTag: LengthTagLoopEnd},
+	}
+	if !reflect.DeepEqual(info.Loops, want) {
+		t.Errorf("got %+v, want %+v", info.Loops, want)
+	}
+	if info.LoopStart != 100 || info.LoopLength != 800 || !info.HasLoopStart || !info.HasLoopLength {
+		t.Errorf("got the primary loop (%d, %d), want (100, 800)", info.LoopStart, info.LoopLength)
+	}
+	if info.TotalSamples != 1000 {
+		t.Errorf("got TotalSamples %d, want 1000", info.TotalSamples)
+	}
+}
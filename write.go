@@ -0,0 +1,222 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// SetLoop copies the Ogg/Vorbis, Ogg/Opus or Ogg FLAC stream src to dst with the given LOOPSTART and
+// LOOPLENGTH meta data values. The existing LOOPSTART and LOOPLENGTH comments are replaced, the existing
+// LOOPEND comments are removed, and the other comments and the vendor string are kept.
+//
+// SetLoop rewrites only the pages of the header packets after the identification header, e.g. the comment
+// header and the setup header of Vorbis, with fresh checksums. The other pages are copied byte for byte,
+// unless the headers no longer fit in the original number of pages. In that case, the following pages of
+// the logical stream are renumbered and their checksums are recomputed.
+// When the stream multiplexes several logical streams, SetLoop edits the first one in the same way as
+// Read.
+func SetLoop(dst io.Writer, src io.Reader, loopStart, loopLength int64) error {
+	if loopStart < 0 {
+		return fmt.Errorf("oggloop: LOOPSTART must be non-negative: %d", loopStart)
+	}
+	if loopLength < 0 {
+		return fmt.Errorf("oggloop: LOOPLENGTH must be non-negative: %d", loopLength)
+	}
+	if loopLength > math.MaxInt64-loopStart {
+		return fmt.Errorf("oggloop: LOOPSTART %d plus LOOPLENGTH %d overflows 64-bit integers", loopStart, loopLength)
+	}
+	return rewriteComment(dst, src, func(c *Comments) error {
+		c.Set("LOOPSTART", strconv.FormatInt(loopStart, 10))
+		c.Set("LOOPLENGTH", strconv.FormatInt(loopLength, 10))
+		c.Remove("LOOPEND")
+		return nil
+	})
+}
+
+// StripLoop copies the Ogg/Vorbis, Ogg/Opus or Ogg FLAC stream src to dst without the LOOPSTART,
+// LOOPLENGTH and LOOPEND meta data.
+// The other comments are kept.
+//
+// StripLoop rewrites the header pages in the same way as SetLoop.
+func StripLoop(dst io.Writer, src io.Reader) error {
+	return rewriteComment(dst, src, func(c *Comments) error {
+		c.Remove("LOOPSTART")
+		c.Remove("LOOPLENGTH")
+		c.Remove("LOOPEND")
+		return nil
+	})
+}
+
+// EditComments copies the Ogg/Vorbis, Ogg/Opus or Ogg FLAC stream src to dst with the vendor string and
+// the comments edited by f, e.g. to rename a track, add ARTIST and fix the loop tags at once.
+//
+// EditComments returns an error if a comment edited by f is not in the form of KEY=value, or the key has
+// a character other than ASCII 0x20 through 0x7D except '='.
+//
+// EditComments rewrites the header pages in the same way as SetLoop. A comment header packet can span
+// multiple pages, and a page is split when it would exceed 255 segments.
+func EditComments(dst io.Writer, src io.Reader, f func(c *Comments)) error {
+	return rewriteComment(dst, src, func(c *Comments) error {
+		f(c)
+		return c.validate()
+	})
+}
+
+// rewriteComment copies the Ogg/Vorbis, Ogg/Opus or Ogg FLAC stream src to dst with the comment header
+// edited by f.
+func rewriteComment(dst io.Writer, src io.Reader, f func(c *Comments) error) error {
+	pr := newPageReader(src, nil)
+	r := newPacketReader(pr)
+	var (
+		c      codec
+		serial uint32
+
+		// minPackets and maxPackets are the numbers of the header packets after the identification
+		// header. maxPackets is 0 when it is unknown.
+		minPackets int
+		maxPackets int
+
+		// headerSeq is the sequence number of the first page after the identification header.
+		headerSeq uint32
+
+		// headerPages is the number of the original pages for the header packets after the
+		// identification header.
+		headerPages int
+
+		packets [][]byte
+
+		// seqDelta is the difference of the numbers of the pages for the headers, which is used for
+		// renumbering the following pages.
+		seqDelta uint32
+		done     bool
+	)
+
+	for {
+		p, pagePackets, err := r.next(nil)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if c == 0 {
+			if p.headerType&headerTypeBOS == 0 {
+				return ErrNoVorbisStream
+			}
+			c = detectCodec(p.body)
+			switch c {
+			case codecVorbis:
+				// The comment header and the setup header.
+				minPackets, maxPackets = 2, 2
+			case codecOpus:
+				// The comment header. The first audio packet begins a fresh page.
+				minPackets, maxPackets = 1, 1
+			case codecFLAC:
+				// The comment header and the other metadata blocks. The identification header has the
+				// number of them, or 0 when it is unknown.
+				// https://xiph.org/flac/ogg_mapping.html
+				minPackets = 1
+				if len(p.body) >= 9 {
+					maxPackets = int(binary.BigEndian.Uint16(p.body[7:9]))
+				}
+			}
+			serial = p.serial
+			if _, err := dst.Write(p.raw); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if p.serial != serial || done {
+			// A later chain can reuse the serial number. Renumber only the pages of the edited stream.
+			if p.serial == serial && p.headerType&headerTypeBOS != 0 {
+				seqDelta = 0
+			}
+			b := p.raw
+			if p.serial == serial && seqDelta != 0 {
+				p.seq += seqDelta
+				b = p.bytes()
+			}
+			if _, err := dst.Write(b); err != nil {
+				return err
+			}
+			if p.serial == serial && p.headerType&headerTypeEOS != 0 {
+				seqDelta = 0
+			}
+			continue
+		}
+
+		// Collect the header packets until they end a page.
+		if headerPages == 0 {
+			headerSeq = p.seq
+		}
+		headerPages++
+		packets = append(packets, pagePackets...)
+		if len(packets) < minPackets || r.partialSize(serial) > 0 {
+			continue
+		}
+		if maxPackets > 0 && len(packets) > maxPackets {
+			return fmt.Errorf("%w: the header packets must end a page", ErrCorruptPage)
+		}
+
+		data, ok := commentData(c, packets[0])
+		if !ok {
+			return ErrNoCommentHeader
+		}
+		vc, err := parseComment(data)
+		if err != nil {
+			return err
+		}
+		if err := f(&vc.Comments); err != nil {
+			return err
+		}
+		// Keep the signature, or the metadata block header of FLAC.
+		header := packets[0][:len(packets[0])-len(data)]
+		packets[0] = append(append([]byte{}, header...), vc.bytes()...)
+		if c == codecFLAC {
+			n := len(packets[0]) - len(header)
+			if n >= 1<<24 {
+				return fmt.Errorf("oggloop: the comment header is too large: %d bytes", n)
+			}
+			packets[0][1], packets[0][2], packets[0][3] = byte(n>>16), byte(n>>8), byte(n)
+		}
+
+		pages := paginate(packets, serial, headerSeq, headerPages)
+		for _, p := range pages {
+			if _, err := dst.Write(p.bytes()); err != nil {
+				return err
+			}
+		}
+		seqDelta = uint32(len(pages) - headerPages)
+		done = true
+	}
+
+	if pr.pages == 0 {
+		return ErrNotOgg
+	}
+	if c == 0 {
+		return ErrNoVorbisStream
+	}
+	if !done {
+		return ErrNoCommentHeader
+	}
+	return nil
+}
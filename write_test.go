@@ -0,0 +1,337 @@
+// Copyright 2026 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oggloop
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetLoop(t *testing.T) {
+	data := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 2)
+	for _, tc := range []struct {
+		start, length int64
+	}{
+		{-1, 2},
+		{1, -2},
+		{math.MaxInt64, 1},
+	} {
+		var buf bytes.Buffer
+		if err := SetLoop(&buf, bytes.NewReader(data), tc.start, tc.length); err == nil {
+			t.Errorf("SetLoop(%d, %d): got no error", tc.start, tc.length)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("SetLoop(%d, %d): wrote %d bytes", tc.start, tc.length, buf.Len())
+		}
+	}
+
+	// Only the first logical stream is edited, and the pages of the other logical streams are copied as
+	// they are.
+	src := testMultiplexed()
+	var buf bytes.Buffer
+	if err := SetLoop(&buf, bytes.NewReader(src), 12, 34); err != nil {
+		t.Fatal(err)
+	}
+	ident2, comment2 := testVorbisHeaders([]string{"LOOPSTART=2", "LOOPLENGTH=20"})
+	for _, p := range [][]byte{
+		testPage(headerTypeBOS, 0, 2, 0, ident2),
+		testPage(0, 0, 2, 1, comment2, []byte("\x05vorbis")),
+		testPage(headerTypeEOS, 2000, 2, 2, make([]byte, 100)),
+	} {
+		if !bytes.Contains(buf.Bytes(), p) {
+			t.Errorf("SetLoop changed a page of the serial number 2")
+		}
+	}
+	if got, want := pageSequences(t, buf.Bytes()), pageSequences(t, src); !reflect.DeepEqual(got, want) {
+		t.Errorf("got the pages %v, want %v", got, want)
+	}
+	for _, tc := range []struct {
+		serial        uint32
+		start, length int64
+	}{
+		{1, 12, 34},
+		{2, 2, 20},
+	} {
+		start, length, err := NewDecoder(bytes.NewReader(buf.Bytes()), &DecoderOptions{Serial: tc.serial, HasSerial: true}).Read()
+		if err != nil {
+			t.Errorf("Read of the serial number %d: %v", tc.serial, err)
+			continue
+		}
+		if start != tc.start || length != tc.length {
+			t.Errorf("Read of the serial number %d: got (%d, %d), want (%d, %d)", tc.serial, start, length, tc.start, tc.length)
+		}
+	}
+}
+
+func TestSetLoopCodecs(t *testing.T) {
+	comments := []string{"TITLE=x", "LOOPSTART=1", "LOOPEND=2"}
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Vorbis",
+			data: testVorbis(comments, 2),
+		},
+		{
+			name: "Opus",
+			data: testOpus(comments, 2),
+		},
+		{
+			name: "FLAC",
+			data: testOggFLAC(comments, 2),
+		},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := SetLoop(&buf, bytes.NewReader(tc.data), 12, 34); err != nil {
+			t.Errorf("%s: SetLoop: %v", tc.name, err)
+			continue
+		}
+		start, length, err := Read(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Errorf("%s: Read after SetLoop: %v", tc.name, err)
+			continue
+		}
+		if start != 12 || length != 34 {
+			t.Errorf("%s: Read after SetLoop: got (%d, %d), want (12, 34)", tc.name, start, length)
+		}
+		c, err := ReadComments(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Errorf("%s: ReadComments after SetLoop: %v", tc.name, err)
+			continue
+		}
+		if got, want := c, map[string][]string{"TITLE": {"x"}, "LOOPSTART": {"12"}, "LOOPLENGTH": {"34"}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ReadComments after SetLoop: got %q, want %q", tc.name, got, want)
+		}
+		// The audio pages are copied as they are.
+		audio := 2 * len(testPage(0, 0, 0, 0, make([]byte, 100)))
+		if got, want := buf.Bytes()[buf.Len()-audio:], tc.data[len(tc.data)-audio:]; !bytes.Equal(got, want) {
+			t.Errorf("%s: SetLoop changed the audio pages", tc.name)
+		}
+
+		buf.Reset()
+		if err := StripLoop(&buf, bytes.NewReader(tc.data)); err != nil {
+			t.Errorf("%s: StripLoop: %v", tc.name, err)
+			continue
+		}
+		if _, _, err := Read(bytes.NewReader(buf.Bytes())); err != ErrNoLoopTags {
+			t.Errorf("%s: Read after StripLoop: got %v, want %v", tc.name, err, ErrNoLoopTags)
+		}
+	}
+}
+
+// pageSequences returns the serial numbers and the sequence numbers of the pages in data, reading it with
+// CRC verification.
+func pageSequences(t *testing.T, data []byte) [][2]uint32 {
+	t.Helper()
+	var seqs [][2]uint32
+	r := NewPageReader(bytes.NewReader(data), &DecoderOptions{VerifyCRC: true})
+	for {
+		p, err := r.NextPage()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		seqs = append(seqs, [2]uint32{p.Serial, p.Sequence})
+	}
+	return seqs
+}
+
+func TestEditCommentsChained(t *testing.T) {
+	// Both chains use the same serial number.
+	first := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 2)
+	second := testVorbis([]string{"LOOPSTART=3", "LOOPLENGTH=4"}, 3)
+	src := append(append([]byte{}, first...), second...)
+
+	var buf bytes.Buffer
+	// The comment no longer fits in a page, so the following pages of the first chain are renumbered.
+	if err := EditComments(&buf, bytes.NewReader(src), func(c *Comments) {
+		c.Add("DESCRIPTION", strings.Repeat("x", 70000))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := pageSequences(t, buf.Bytes())
+	want := pageSequences(t, src)
+	// The header of the first chain takes one more page.
+	firstPages := len(pageSequences(t, first))
+	want = append(append(want[:1:1], [2]uint32{1234, 1}), want[1:]...)
+	for i := 2; i < firstPages+1; i++ {
+		want[i][1]++
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	rs, err := ReadAll(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 2 || rs[0].Info.LoopStart != 1 || rs[1].Info.LoopStart != 3 {
+		t.Errorf("ReadAll: got %+v", rs)
+	}
+}
+
+// probeVerified probes data with CRC verification, and returns the only logical stream.
+func probeVerified(t *testing.T, data []byte) StreamReport {
+	t.Helper()
+	r, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{VerifyCRC: true}).Probe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Streams) != 1 {
+		t.Fatalf("got %d streams, want 1", len(r.Streams))
+	}
+	return r.Streams[0]
+}
+
+func TestEditComments(t *testing.T) {
+	comments := []string{"TITLE=x", "LOOPSTART=1", "LOOPEND=2"}
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Vorbis",
+			data: testVorbis(comments, 2),
+		},
+		{
+			name: "Opus",
+			data: testOpus(comments, 2),
+		},
+		{
+			name: "FLAC",
+			data: testOggFLAC(comments, 2),
+		},
+	}
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := EditComments(&buf, bytes.NewReader(tc.data), func(c *Comments) {
+			c.Vendor = "oggloop"
+			c.Set("TITLE", "y")
+			c.Add("ARTIST", "z")
+			c.Remove("LOOPEND")
+			c.Add("LOOPLENGTH", "3")
+		}); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		s := probeVerified(t, buf.Bytes())
+		if s.Vendor != "oggloop" {
+			t.Errorf("%s: got vendor %q, want %q", tc.name, s.Vendor, "oggloop")
+		}
+		if got, want := s.Comments, map[string][]string{"TITLE": {"y"}, "LOOPSTART": {"1"}, "ARTIST": {"z"}, "LOOPLENGTH": {"3"}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", tc.name, got, want)
+		}
+		if got, want := pageSequences(t, buf.Bytes()), pageSequences(t, tc.data); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got pages %v, want %v", tc.name, got, want)
+		}
+	}
+}
+
+func TestEditCommentsLargeComment(t *testing.T) {
+	src := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 3)
+	large := strings.Repeat("x", 140000)
+
+	// The comment header takes more than 255 segments, so it is split across three pages.
+	var grown bytes.Buffer
+	if err := EditComments(&grown, bytes.NewReader(src), func(c *Comments) {
+		c.Add("DESCRIPTION", large)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	seqs := pageSequences(t, grown.Bytes())
+	if got, want := len(seqs), len(pageSequences(t, src))+2; got != want {
+		t.Errorf("got %d pages, want %d", got, want)
+	}
+	for i, s := range seqs {
+		if s[1] != uint32(i) {
+			t.Errorf("page %d: got sequence number %d, want %d", i, s[1], i)
+		}
+	}
+	r := NewPageReader(bytes.NewReader(grown.Bytes()), &DecoderOptions{VerifyCRC: true})
+	for {
+		p, err := r.NextPage()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Segments) > 255 {
+			t.Errorf("page %d: got %d segments", p.Sequence, len(p.Segments))
+		}
+	}
+	s := probeVerified(t, grown.Bytes())
+	if got := s.Comments["DESCRIPTION"]; len(got) != 1 || got[0] != large {
+		t.Errorf("DESCRIPTION is not kept")
+	}
+	if s.TotalSamples != 3000 {
+		t.Errorf("got TotalSamples %d, want 3000", s.TotalSamples)
+	}
+
+	// Shrink the comment header back. The two header packets no longer fill three pages, so they take one
+	// page, and the following pages are renumbered by a negative difference.
+	var shrunk bytes.Buffer
+	if err := EditComments(&shrunk, bytes.NewReader(grown.Bytes()), func(c *Comments) {
+		c.Remove("DESCRIPTION")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pageSequences(t, shrunk.Bytes()), pageSequences(t, src); !reflect.DeepEqual(got, want) {
+		t.Errorf("got pages %v, want %v", got, want)
+	}
+	if !bytes.Equal(shrunk.Bytes(), src) {
+		t.Errorf("shrinking the comment header back does not restore the original stream")
+	}
+}
+
+func TestEditCommentsInvalid(t *testing.T) {
+	testCases := []struct {
+		field string
+		valid bool
+	}{
+		{field: "KEY=value", valid: true},
+		{field: "KEY=a=b", valid: true},
+		{field: "KEY=", valid: true},
+		{field: " !}=value", valid: true},
+		{field: "KEY=\x00\xff", valid: true},
+		{field: "KEY", valid: false},
+		{field: "", valid: false},
+		{field: "KE\x1fY=value", valid: false},
+		{field: "KEY~=value", valid: false},
+		{field: "KEY\x7f=value", valid: false},
+		{field: "KÉY=value", valid: false},
+	}
+	src := testVorbis([]string{"LOOPSTART=1", "LOOPLENGTH=2"}, 1)
+	for _, tc := range testCases {
+		err := EditComments(io.Discard, bytes.NewReader(src), func(c *Comments) {
+			c.Fields = append(c.Fields, tc.field)
+		})
+		if tc.valid && err != nil {
+			t.Errorf("%q: %v", tc.field, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%q: got nil, want an error", tc.field)
+		}
+	}
+}